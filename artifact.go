@@ -0,0 +1,120 @@
+package realize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Binary describes the main package of a project
+type binary struct {
+	pkg    string // import path of the main package
+	target string // destination used by go install
+	slot   string // blue/green slot of the artifact
+}
+
+// Output returns the directory where the project artifacts are built
+func (p *Project) output() string {
+	if p.Output != "" {
+		if filepath.IsAbs(p.Output) {
+			return p.Output
+		}
+		dir, _ := filepath.Abs(filepath.Join(p.Path, p.Output))
+		return dir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	abs, _ := filepath.Abs(p.Path)
+	h := fnv.New32a()
+	h.Write([]byte(abs))
+	return filepath.Join(base, RPrefix, fmt.Sprintf("%s-%x", filepath.Base(abs), h.Sum32()))
+}
+
+// Artifact returns the path of the binary built for the main package
+func (p *Project) artifact(bin binary) string {
+	name := path.Base(bin.pkg)
+	if bin.slot != "" {
+		name += "-" + bin.slot
+	}
+	if runtime.GOOS == "windows" {
+		name += RExtWin
+	}
+	return filepath.Join(p.output(), name)
+}
+
+// Executable returns the path of the binary launched by the run step
+func (p *Project) executable(bin binary) string {
+	if p.Tools.Run.Method != "" {
+		return p.Tools.Run.Method
+	}
+	if bin.pkg == "" {
+		return ""
+	}
+	if p.Tools.Build.Status {
+		return p.artifact(bin)
+	}
+	return bin.target
+}
+
+// Binary resolves the main package of the project with go list
+func (p *Project) binary() (bin binary, err error) {
+	pattern := "."
+	if p.Tools.Run.Path != "" {
+		pattern, _ = filepath.Abs(p.Tools.Run.Path)
+	}
+	mains, err := list(p.Path, pattern)
+	if err != nil {
+		return
+	}
+	if len(mains) == 0 && p.Tools.Run.Path == "" {
+		if mains, err = list(p.Path, "./..."); err != nil {
+			return
+		}
+	}
+	switch len(mains) {
+	case 0:
+		err = errors.New("main package not found")
+	case 1:
+		bin = mains[0]
+	default:
+		var pkgs []string
+		for _, m := range mains {
+			pkgs = append(pkgs, m.pkg)
+		}
+		err = errors.New("multiple main packages found, set the run path: " + strings.Join(pkgs, ", "))
+	}
+	return
+}
+
+// List the main packages matching a pattern
+func list(dir, pattern string) (mains []binary, err error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.Name}} {{.ImportPath}} {{.Target}}", pattern)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.New(stderr.String() + err.Error())
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 || fields[0] != "main" {
+			continue
+		}
+		bin := binary{pkg: fields[1]}
+		if len(fields) == 3 {
+			bin.target = fields[2]
+		}
+		mains = append(mains, bin)
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package realize
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProject_Output(t *testing.T) {
+	p := Project{Path: "."}
+	a, b := p.output(), (&Project{Path: "cmd"}).output()
+	if a == b {
+		t.Error("Unexpected error", "output dirs should differ", a, b)
+	}
+	p.Output = "bin"
+	expected, _ := filepath.Abs("bin")
+	if p.output() != expected {
+		t.Error("Expected", expected, "instead", p.output())
+	}
+}
+
+func TestProject_Binary(t *testing.T) {
+	p := Project{Path: "."}
+	bin, err := p.binary()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if bin.pkg != "github.com/grzegorz-zur/realize/cmd/realize" {
+		t.Error("Unexpected main package", bin.pkg)
+	}
+	p.Tools.Build.Status = true
+	if !strings.HasPrefix(p.executable(bin), p.output()) {
+		t.Error("Unexpected executable", p.executable(bin))
+	}
+	p.Tools.Run.Path = "tools.go"
+	if _, err := p.binary(); err == nil {
+		t.Error("Error expected")
+	}
+}
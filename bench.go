@@ -0,0 +1,310 @@
+package realize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bench defines the benchmarks run by the bench tool and how they are compared
+type Bench struct {
+	Regex     string  `yaml:"regex,omitempty" json:"regex,omitempty"`
+	Count     int     `yaml:"count,omitempty" json:"count,omitempty"`
+	Threshold float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	Baseline  string  `yaml:"baseline,omitempty" json:"baseline,omitempty"`
+	Pin       bool    `yaml:"pin,omitempty" json:"pin,omitempty"`
+}
+
+// Alpha is the significance level of the comparison
+const alpha = 0.05
+
+// Samples of a benchmark run indexed by benchmark and unit
+type samples map[string]map[string][]float64
+
+// Benchmarks keeps the benchmark runs of a session
+type benchmarks struct {
+	sync.Mutex
+	baseline samples
+	first    map[string]samples
+	prev     map[string]samples
+}
+
+// Comparison of a benchmark metric between two runs
+type comparison struct {
+	name  string
+	unit  string
+	old   []float64
+	new   []float64
+	delta float64
+	p     float64
+}
+
+// ParseBench reads the results of a go test -bench output
+func parseBench(r io.Reader) samples {
+	result := make(samples)
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "pkg: ") {
+			pkg = strings.TrimSpace(line[len("pkg: "):])
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := fields[0]
+		if pkg != "" {
+			name = pkg + " " + name
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			if result[name] == nil {
+				result[name] = make(map[string][]float64)
+			}
+			result[name][fields[i+1]] = append(result[name][fields[i+1]], v)
+		}
+	}
+	return result
+}
+
+// Compare two benchmark runs, only benchmarks present in both are compared
+func compare(old, new samples) (result []comparison) {
+	for name, units := range new {
+		for unit, values := range units {
+			prev := old[name][unit]
+			if len(prev) == 0 {
+				continue
+			}
+			c := comparison{name: name, unit: unit, old: prev, new: values, p: utest(prev, values)}
+			if m := mean(prev); m != 0 {
+				c.delta = (mean(values) - m) / m * 100
+			}
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].name == result[j].name {
+			return result[i].unit < result[j].unit
+		}
+		return result[i].name < result[j].name
+	})
+	return
+}
+
+// String formats the comparison as benchstat does
+func (c comparison) String() string {
+	change := "~"
+	if c.p < alpha {
+		change = fmt.Sprintf("%+.2f%%", c.delta)
+	}
+	return fmt.Sprintf("%s %s %s → %s %s (p=%.3f n=%d+%d)", c.name, c.unit, metric(mean(c.old)), metric(mean(c.new)), change, c.p, len(c.old), len(c.new))
+}
+
+// Worse returns the change of the metric, positive when it got worse
+func (c comparison) worse() float64 {
+	// throughput units are better when higher
+	if strings.HasSuffix(c.unit, "/s") {
+		return -c.delta
+	}
+	return c.delta
+}
+
+// Metric formats a value with a SI prefix
+func metric(v float64) string {
+	prefixes := []string{"", "k", "M", "G", "T"}
+	i := 0
+	for math.Abs(v) >= 1000 && i < len(prefixes)-1 {
+		v /= 1000
+		i++
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64) + prefixes[i]
+}
+
+// Mean of a sample
+func mean(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
+
+// Utest returns the two-sided p-value of the Mann-Whitney U test
+func utest(a, b []float64) float64 {
+	m, n := len(a), len(b)
+	if m == 0 || n == 0 {
+		return 1
+	}
+	type value struct {
+		v     float64
+		first bool
+	}
+	all := make([]value, 0, m+n)
+	for _, v := range a {
+		all = append(all, value{v, true})
+	}
+	for _, v := range b {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+	// ranks, ties get the average rank
+	var r1, ties float64
+	tied := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				r1 += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			tied = true
+			ties += t*t*t - t
+		}
+		i = j
+	}
+	u := r1 - float64(m*(m+1))/2
+	if !tied && m+n <= 50 {
+		return exactU(m, n, u)
+	}
+	// normal approximation with tie and continuity correction
+	mu := float64(m*n) / 2
+	N := float64(m + n)
+	sigma := math.Sqrt(float64(m*n) / 12 * (N + 1 - ties/(N*(N-1))))
+	if sigma == 0 {
+		return 1
+	}
+	z := (math.Abs(u-mu) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// ExactU computes the two-sided p-value of U from its exact distribution
+func exactU(m, n int, u float64) float64 {
+	// f[i][j][k] is the number of arrangements of i and j values with U = k,
+	// computed one row of i at a time
+	max := m * n
+	prev := make([][]float64, n+1)
+	for j := range prev {
+		prev[j] = make([]float64, max+1)
+		prev[j][0] = 1
+	}
+	for i := 1; i <= m; i++ {
+		cur := make([][]float64, n+1)
+		cur[0] = make([]float64, max+1)
+		cur[0][0] = 1
+		for j := 1; j <= n; j++ {
+			cur[j] = make([]float64, max+1)
+			for k := 0; k <= i*j; k++ {
+				cur[j][k] = cur[j-1][k]
+				if k >= j {
+					cur[j][k] += prev[j][k-j]
+				}
+			}
+		}
+		prev = cur
+	}
+	dist := prev[n]
+	total := 0.0
+	for _, v := range dist {
+		total += v
+	}
+	k := int(math.Round(u))
+	var lower, upper float64
+	for i, v := range dist {
+		if i <= k {
+			lower += v
+		}
+		if i >= k {
+			upper += v
+		}
+	}
+	return math.Min(1, 2*math.Min(lower, upper)/total)
+}
+
+// Benchmark compares the results of a bench run with the baseline of the session
+func (p *Project) benchmark(t *Tool, path string, output string) {
+	if p.bench == nil {
+		p.bench = &benchmarks{first: make(map[string]samples), prev: make(map[string]samples)}
+	}
+	b := p.bench
+	b.Lock()
+	defer b.Unlock()
+	cur := parseBench(strings.NewReader(output))
+	if len(cur) == 0 {
+		return
+	}
+	options := p.Tools.Bench.Benchmarks
+	against := "previous run"
+	old := b.prev[path]
+	if options.Baseline != "" {
+		if b.baseline == nil {
+			file := options.Baseline
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(p.Path, file)
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				p.Err(err)
+				return
+			}
+			b.baseline = parseBench(f)
+			f.Close()
+		}
+		old, against = b.baseline, "baseline "+options.Baseline
+	} else if options.Pin && b.first[path] != nil {
+		old, against = b.first[path], "first run"
+	}
+	if b.first[path] == nil {
+		b.first[path] = cur
+	}
+	b.prev[path] = cur
+	if old == nil {
+		return
+	}
+	var lines []string
+	var regressions []comparison
+	for _, c := range compare(old, cur) {
+		lines = append(lines, c.String())
+		if options.Threshold > 0 && c.p < alpha && c.worse() > options.Threshold {
+			regressions = append(regressions, c)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), "compared with", Magenta.Regular(against), ":", Magenta.Bold(path))
+	out = BufferOut{Time: time.Now(), Text: "compared with " + against, Path: path, Type: t.name, Stream: strings.Join(lines, "\n")}
+	p.stamp("log", out, msg, strings.Join(lines, "\n"))
+	for _, c := range regressions {
+		text := fmt.Sprintf("%s %s regressed %.2f%% above threshold %.2f%%", c.name, c.unit, c.worse(), options.Threshold)
+		msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold(t.name), Red.Regular(text))
+		out = BufferOut{Time: time.Now(), Text: text, Path: path, Type: t.name, Stream: c.String()}
+		p.stamp("error", out, msg, c.String())
+	}
+}
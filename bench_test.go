@@ -0,0 +1,98 @@
+package realize
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBench(t *testing.T) {
+	output := `goos: linux
+goarch: amd64
+pkg: example.com/a
+BenchmarkParse-8   	 1000000	      1052 ns/op	     128 B/op	       2 allocs/op
+BenchmarkParse-8   	 1000000	      1048 ns/op	     128 B/op	       2 allocs/op
+BenchmarkCopy-8    	     500	   2000000 ns/op	 500.00 MB/s
+PASS
+ok  	example.com/a	3.012s
+`
+	result := parseBench(strings.NewReader(output))
+	parse := result["example.com/a BenchmarkParse-8"]
+	if len(parse["ns/op"]) != 2 || parse["ns/op"][1] != 1048 || len(parse["allocs/op"]) != 2 {
+		t.Error("Unexpected samples", parse)
+	}
+	if result["example.com/a BenchmarkCopy-8"]["MB/s"][0] != 500 {
+		t.Error("Unexpected samples", result)
+	}
+}
+
+func TestUtest(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{6, 7, 8, 9, 10}
+	if p := utest(a, b); math.Abs(p-0.0079) > 0.0001 {
+		t.Error("Unexpected p-value", p)
+	}
+	if p := utest(a, a); p != 1 {
+		t.Error("Unexpected p-value", p)
+	}
+	if p := utest([]float64{1}, []float64{2}); p != 1 {
+		t.Error("Unexpected p-value", p)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := samples{"BenchmarkA": {"ns/op": {100, 101, 99, 100, 102}, "MB/s": {10, 10, 10, 10, 10}}}
+	new := samples{"BenchmarkA": {"ns/op": {120, 121, 119, 122, 120}, "MB/s": {8, 8, 8, 8, 8}}, "BenchmarkB": {"ns/op": {1}}}
+	result := compare(old, new)
+	if len(result) != 2 || result[0].unit != "MB/s" || result[1].unit != "ns/op" {
+		t.Fatal("Unexpected comparison", result)
+	}
+	if result[0].worse() != 20 || math.Abs(result[1].worse()-19.92) > 0.01 || result[1].p >= alpha {
+		t.Error("Unexpected comparison", result[0].worse(), result[1].worse(), result[1].p)
+	}
+	if !strings.Contains(result[1].String(), "100.400 → 120.400 +19.92%") {
+		t.Error("Unexpected format", result[1].String())
+	}
+}
+
+func TestTools_SetupBenchCount(t *testing.T) {
+	tools := Tools{Bench: BenchTool{Tool: Tool{Status: true}}}
+	tools.Setup()
+	count := ""
+	for i, arg := range tools.Bench.cmd {
+		if arg == "-count" && i+1 < len(tools.Bench.cmd) {
+			count = tools.Bench.cmd[i+1]
+		}
+	}
+	if count != "5" {
+		t.Fatal("Unexpected default count", count)
+	}
+	// the default count is enough to flag a regression
+	a, b := make([]float64, 5), make([]float64, 5)
+	for i := range a {
+		a[i], b[i] = float64(100+i), float64(200+i)
+	}
+	if p := utest(a, b); p >= alpha {
+		t.Error("Expected a significant difference with the default count", p)
+	}
+}
+
+func TestProject_ReloadBench(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":    "module example.com/a\n",
+		"a.go":      "package a\n\nfunc A() int { return 1 }\n",
+		"a_test.go": "package a\nimport \"testing\"\nfunc BenchmarkA(b *testing.B) { for i := 0; i < b.N; i++ { A() } }\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir}
+	p.Tools.Bench = BenchTool{Tool: Tool{Status: true, Args: []string{"-benchtime=1x"}}, Benchmarks: Bench{Count: 1}}
+	p.Tools.Setup()
+	// a change of the code under benchmark runs the benchmarks of its package
+	p.Reload(filepath.Join(dir, "a.go"), make(chan bool))
+	if p.bench == nil || p.bench.prev[dir] == nil {
+		t.Error("Expected the benchmarks of the package run")
+	}
+}
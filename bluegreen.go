@@ -0,0 +1,203 @@
+package realize
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bluegreen defines how a new process replaces the running one, ready is an http url or a tcp address
+// with a {port} replaced by the port of the slot, the blue one first in ports, given to the process as REALIZE_PORT
+type Bluegreen struct {
+	Status  bool     `yaml:"status,omitempty" json:"status,omitempty"`
+	Ready   string   `yaml:"ready,omitempty" json:"ready,omitempty"`
+	Ports   []string `yaml:"ports,omitempty" json:"ports,omitempty"`
+	Delay   string   `yaml:"delay,omitempty" json:"delay,omitempty"`
+	Timeout string   `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Deployment is the process serving in blue/green mode and the processes started by slot stop channel
+type deployment struct {
+	sync.Mutex
+	slot string
+	stop chan bool
+	cmds map[<-chan bool]*exec.Cmd
+}
+
+// Slots of the artifacts
+const (
+	blue  = "blue"
+	green = "green"
+)
+
+// Duration parses a duration with a default value
+func duration(s string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// Next returns the slot not used by the running process
+func (p *Project) next() string {
+	if p.deploy == nil {
+		return blue
+	}
+	p.deploy.Lock()
+	defer p.deploy.Unlock()
+	if p.deploy.slot == blue {
+		return green
+	}
+	return blue
+}
+
+// Port returns the port of a slot
+func (b Bluegreen) port(slot string) string {
+	if len(b.Ports) != 2 {
+		return ""
+	}
+	if slot == green {
+		return b.Ports[1]
+	}
+	return b.Ports[0]
+}
+
+// Endpoint returns the readiness endpoint of a slot, the old process mustn't answer for the new one
+func (b Bluegreen) endpoint(slot string) (string, error) {
+	if b.Ready == "" {
+		return "", nil
+	}
+	if !strings.Contains(b.Ready, "{port}") || b.port(slot) == "" {
+		return "", errors.New("bluegreen ready needs a {port} and two ports, one by slot")
+	}
+	return strings.Replace(b.Ready, "{port}", b.port(slot), -1), nil
+}
+
+// Probe reports whether the process answers on the readiness endpoint
+func probe(ready string) bool {
+	if strings.HasPrefix(ready, "http://") || strings.HasPrefix(ready, "https://") {
+		client := http.Client{Timeout: time.Second}
+		resp, err := client.Get(ready)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode < 400
+	}
+	conn, err := net.DialTimeout("tcp", ready, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Wait blocks until the new process is ready on its endpoint, without endpoint it must survive the delay
+func (b Bluegreen) wait(ready string, exited <-chan bool, stop <-chan bool) error {
+	timeout := time.NewTimer(duration(b.Timeout, 30*time.Second))
+	defer timeout.Stop()
+	delay := time.NewTimer(duration(b.Delay, time.Second))
+	defer delay.Stop()
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-exited:
+			return errors.New("new process exited before being ready")
+		case <-stop:
+			return errors.New("new process replaced by a newer change")
+		case <-timeout.C:
+			return errors.New("new process not ready in time")
+		case <-delay.C:
+			if ready == "" {
+				return nil
+			}
+		case <-tick.C:
+			if ready != "" && probe(ready) {
+				return nil
+			}
+		}
+	}
+}
+
+// Swap starts the new process and stops the old one when the new is ready, otherwise the old one keeps serving
+func (p *Project) swap(bin binary, stop <-chan bool) {
+	b := p.Tools.Run.Bluegreen
+	ready, err := b.endpoint(bin.slot)
+	if err != nil {
+		p.Err(err)
+		return
+	}
+	env := []string{"REALIZE_SLOT=" + bin.slot}
+	if port := b.port(bin.slot); port != "" {
+		env = append(env, "REALIZE_PORT="+port)
+	}
+	next := make(chan bool)
+	exited := p.launch(bin, next, env...)
+	if err := b.wait(ready, exited, stop); err != nil {
+		close(next)
+		p.Err(err)
+		return
+	}
+	p.deploy.Lock()
+	old := p.deploy.stop
+	p.deploy.slot, p.deploy.stop = bin.slot, next
+	cmd := p.deploy.cmds[next]
+	p.deploy.Unlock()
+	// the signals go to the serving process
+	if cmd != nil {
+		p.started(cmd)
+	}
+	if old != nil {
+		close(old)
+	}
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold("Run"), "switched to", Magenta.Bold(bin.slot))
+	out = BufferOut{Time: time.Now(), Text: "Run switched to " + bin.slot}
+	p.stamp("log", out, msg, "")
+}
+
+// Launched records a process started for a slot, it's the running one only once it serves
+func (p *Project) launched(cmd *exec.Cmd, stop <-chan bool) {
+	if p.deploy == nil {
+		p.started(cmd)
+		return
+	}
+	p.deploy.Lock()
+	if p.deploy.cmds == nil {
+		p.deploy.cmds = make(map[<-chan bool]*exec.Cmd)
+	}
+	p.deploy.cmds[stop] = cmd
+	serving := p.deploy.stop != nil && p.deploy.stop == stop
+	p.deploy.Unlock()
+	if serving {
+		p.started(cmd)
+	}
+}
+
+// Ended forgets a process started for a slot
+func (p *Project) ended(cmd *exec.Cmd, stop <-chan bool) {
+	if p.deploy != nil {
+		p.deploy.Lock()
+		delete(p.deploy.cmds, stop)
+		p.deploy.Unlock()
+	}
+	p.exited(cmd)
+}
+
+// Retire stops the serving process
+func (p *Project) retire() {
+	if p.deploy == nil {
+		return
+	}
+	p.deploy.Lock()
+	defer p.deploy.Unlock()
+	if p.deploy.stop != nil {
+		close(p.deploy.stop)
+		p.deploy.stop = nil
+	}
+}
@@ -0,0 +1,83 @@
+package realize
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestBluegreen_Wait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	b := Bluegreen{Timeout: "2s"}
+	if err := b.wait(server.URL, make(chan bool), make(chan bool)); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	b = Bluegreen{Timeout: "200ms"}
+	if err := b.wait("127.0.0.1:1", make(chan bool), make(chan bool)); err == nil {
+		t.Error("Expected timeout")
+	}
+	exited := make(chan bool)
+	close(exited)
+	b = Bluegreen{Delay: "1h"}
+	if err := b.wait("", exited, make(chan bool)); err == nil {
+		t.Error("Expected exit error")
+	}
+	start := time.Now()
+	b = Bluegreen{Delay: "50ms"}
+	if err := b.wait("", make(chan bool), make(chan bool)); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Error("Unexpected delay", err)
+	}
+}
+
+func TestProject_Next(t *testing.T) {
+	p := Project{Path: "/app", deploy: &deployment{}}
+	if p.next() != blue {
+		t.Error("Unexpected first slot")
+	}
+	p.deploy.slot = blue
+	if p.next() != green {
+		t.Error("Unexpected next slot")
+	}
+	if p.artifact(binary{pkg: "example.com/app", slot: green}) != p.artifact(binary{pkg: "example.com/app-green"}) {
+		t.Error("Unexpected slot artifact")
+	}
+}
+
+func TestBluegreen_Endpoint(t *testing.T) {
+	b := Bluegreen{Ready: "http://127.0.0.1:{port}/ready", Ports: []string{"8081", "8082"}}
+	if e, err := b.endpoint(blue); err != nil || e != "http://127.0.0.1:8081/ready" {
+		t.Error("Unexpected blue endpoint", e, err)
+	}
+	if e, err := b.endpoint(green); err != nil || e != "http://127.0.0.1:8082/ready" {
+		t.Error("Unexpected green endpoint", e, err)
+	}
+	// both slots would answer on the same address
+	b = Bluegreen{Ready: "127.0.0.1:8080"}
+	if _, err := b.endpoint(blue); err == nil {
+		t.Error("Expected an error for a shared endpoint")
+	}
+	if e, err := (Bluegreen{}).endpoint(blue); err != nil || e != "" {
+		t.Error("Unexpected endpoint without readiness", e, err)
+	}
+}
+
+func TestProject_Launched(t *testing.T) {
+	serving := make(chan bool)
+	old, cmd := exec.Command("old"), exec.Command("new")
+	p := Project{deploy: &deployment{stop: serving}, process: &process{}}
+	p.launched(old, serving)
+	next := make(chan bool)
+	// a process isn't the running one before it's ready
+	p.launched(cmd, next)
+	if p.process.cmd != old {
+		t.Fatal("Unexpected running process", p.process.cmd)
+	}
+	// nor after a failed swap
+	p.ended(cmd, next)
+	if p.process.cmd != old || len(p.deploy.cmds) != 1 {
+		t.Error("Expected the old process still running", p.process.cmd)
+	}
+}
@@ -0,0 +1,57 @@
+// +build !windows
+
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProject_RestartBluegreen(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":  "module example.com/app\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir, Output: "bin", deploy: &deployment{slot: blue}, process: &process{}}
+	p.Tools.Build.Status = true
+	p.Tools.Run.Status = true
+	p.Tools.Run.Bluegreen = Bluegreen{Status: true, Delay: "100ms", Ports: []string{"8081", "8082"}}
+	bin, err := p.binary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(p.output(), Permission)
+	slots := filepath.Join(dir, "slots.txt")
+	// the serving build
+	bin.slot = blue
+	script := "#!/bin/sh\necho $REALIZE_SLOT $REALIZE_PORT $$ >> " + slots + "\nexec sleep 60\n"
+	if err := ioutil.WriteFile(p.artifact(bin), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	p.restart(make(chan bool))
+	// the restarted process doesn't share the slot of the serving one
+	var content []byte
+	for i := 0; i < 100 && len(content) == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+		content, _ = ioutil.ReadFile(slots)
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 || fields[0] != "green" || fields[1] != "8082" || p.deploy.slot != green {
+		t.Error("Unexpected restarted slot", string(content), p.deploy.slot)
+	}
+	p.retire()
+	if len(fields) == 3 {
+		pid, _ := strconv.Atoi(fields[2])
+		for i := 0; i < 100 && syscall.Kill(pid, 0) == nil; i++ {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package realize
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Busy is the reload in progress when the changes don't restart it
+type busy struct {
+	running bool
+	queued  bool
+	action  string
+	path    string
+	done    chan bool
+}
+
+// Policy returns how the changes during a reload are handled, restart is the default
+func (w Watch) policy() string {
+	switch p := strings.ToLower(w.OnBusy); p {
+	case "queue", "ignore":
+		return p
+	}
+	return "restart"
+}
+
+// Hold reports whether the policy keeps the reload in progress, the change is then queued or ignored
+func (p *Project) hold(action, path string) bool {
+	if p.busy == nil || !p.busy.running {
+		return false
+	}
+	text := "reload in progress, change ignored"
+	if p.Watcher.policy() == "queue" {
+		switch {
+		case !p.busy.queued:
+			p.busy.action, p.busy.path = action, path
+		case p.busy.action != action || p.busy.path != path:
+			// different changes run once, as a reload of the whole project
+			p.busy.action, p.busy.path = "reload", ""
+		}
+		p.busy.queued = true
+		text = "reload in progress, change queued"
+	}
+	msg = fmt.Sprintln(p.pname(p.Name, 4), ":", Magenta.Regular(text))
+	out = BufferOut{Time: time.Now(), Text: text}
+	p.stamp("log", out, msg, "")
+	return true
+}
+
+// Reload stops the current work and reloads the project, unless the policy keeps the reload in progress
+func (p *Project) reload(path string) {
+	if p.hold("reload", path) {
+		return
+	}
+	close(p.stop)
+	p.stop = make(chan bool)
+	p.start(path)
+}
+
+// Start a reload, its end is notified when the policy tracks it
+func (p *Project) start(path string) {
+	if p.busy == nil {
+		go p.Reload(path, p.stop)
+		return
+	}
+	p.busy.running = true
+	go func(stop chan bool) {
+		p.Reload(path, stop)
+		p.busy.done <- true
+	}(p.stop)
+}
+
+// Reloaded ends a reload and returns the queued change, if any
+func (p *Project) reloaded() (action, path string) {
+	p.busy.running = false
+	if !p.busy.queued {
+		return "", ""
+	}
+	p.busy.queued = false
+	return p.busy.action, p.busy.path
+}
@@ -0,0 +1,108 @@
+package realize
+
+import (
+	"testing"
+)
+
+func TestWatch_Policy(t *testing.T) {
+	for policy, expected := range map[string]string{"": "restart", "Queue": "queue", "ignore": "ignore", "other": "restart"} {
+		if w := (Watch{OnBusy: policy}); w.policy() != expected {
+			t.Error("Unexpected policy", policy, w.policy())
+		}
+	}
+}
+
+func TestProject_ReloadBusy(t *testing.T) {
+	for _, policy := range []string{"restart", "queue", "ignore"} {
+		reloads := make(chan Context, 10)
+		release := make(chan bool)
+		r := Realize{Reload: func(c Context) {
+			reloads <- c
+			<-release
+		}}
+		p := Project{parent: &r, Name: "test", stop: make(chan bool)}
+		p.Watcher.OnBusy = policy
+		if policy != "restart" {
+			p.busy = &busy{done: make(chan bool, 1)}
+		}
+		p.start("")
+		first := <-reloads
+		p.reload("a.go")
+		p.reload("b.go")
+		switch policy {
+		case "restart":
+			select {
+			case <-first.Stop:
+			default:
+				t.Fatal("Expected the reload in progress to be stopped")
+			}
+			// every change starts a reload
+			paths := map[string]bool{(<-reloads).Path: true, (<-reloads).Path: true}
+			if !paths["a.go"] || !paths["b.go"] {
+				t.Error("Unexpected reloads", paths)
+			}
+			close(release)
+			continue
+		case "queue":
+			release <- true
+			<-p.busy.done
+			// the different changes are coalesced in a reload of the whole project
+			action, path := p.reloaded()
+			if action != "reload" || path != "" {
+				t.Fatal("Unexpected queued change", action, path)
+			}
+			p.reload(path)
+			if c := <-reloads; c.Path != "" {
+				t.Error("Unexpected queued reload", c.Path)
+			}
+			select {
+			case <-first.Stop:
+			default:
+				t.Error("Expected the stop of the finished reload to be closed")
+			}
+			release <- true
+			<-p.busy.done
+			p.reloaded()
+		case "ignore":
+			release <- true
+			<-p.busy.done
+			if action, _ := p.reloaded(); action != "" {
+				t.Error("Unexpected change after an ignored one", action)
+			}
+		}
+		if len(reloads) != 0 || p.busy.running {
+			t.Error("Unexpected reload with policy", policy)
+		}
+	}
+}
+
+func TestProject_Hold(t *testing.T) {
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", stop: make(chan bool)}
+	if p.hold("restart", "web/index.html") {
+		t.Error("Unexpected change held without a policy")
+	}
+	p.busy = &busy{running: true}
+	p.Watcher.OnBusy = "ignore"
+	if !p.hold("retest", "a_test.go") || p.busy.queued {
+		t.Error("Expected the change ignored")
+	}
+	p.Watcher.OnBusy = "queue"
+	// the same change runs once
+	p.hold("retest", "a_test.go")
+	p.hold("retest", "a_test.go")
+	if action, path := p.reloaded(); action != "retest" || path != "a_test.go" {
+		t.Error("Unexpected queued change", action, path)
+	}
+	p.busy.running = true
+	p.hold("restart", "web/index.html")
+	p.hold("retest", "a_test.go")
+	if action, path := p.reloaded(); action != "reload" || path != "" {
+		t.Error("Unexpected coalesced change", action, path)
+	}
+	select {
+	case <-p.stop:
+		t.Error("Unexpected stop of the reload in progress")
+	default:
+	}
+}
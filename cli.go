@@ -3,11 +3,9 @@ package realize
 import (
 	"errors"
 	"fmt"
-	"go/build"
 	"log"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -40,6 +38,8 @@ type (
 		Before   Func        `yaml:"-"  json:"-"`
 		Change   Func        `yaml:"-"  json:"-"`
 		Reload   Func        `yaml:"-"  json:"-"`
+		// tool processes running across the projects
+		scheduler *scheduler
 	}
 
 	// Context is used as argument for func
@@ -60,13 +60,6 @@ func init() {
 	// custom log
 	log.SetFlags(0)
 	log.SetOutput(LogWriter{})
-	if build.Default.GOPATH == "" {
-		log.Fatal("$GOPATH isn't set properly")
-	}
-	path := filepath.SplitList(build.Default.GOPATH)
-	if err := os.Setenv("GOBIN", filepath.Join(path[len(path)-1], "bin")); err != nil {
-		log.Fatal(err)
-	}
 }
 
 // Stop realize workflow
@@ -84,6 +77,9 @@ func (r *Realize) Start() error {
 	if len(r.Schema.Projects) > 0 {
 		var wg sync.WaitGroup
 		wg.Add(len(r.Schema.Projects))
+		if r.Settings.Jobs > 0 {
+			r.scheduler = &scheduler{max: r.Settings.Jobs, changed: make(map[*Project]time.Time)}
+		}
 		for k := range r.Schema.Projects {
 			r.Schema.Projects[k].exit = make(chan os.Signal, 1)
 			signal.Notify(r.Schema.Projects[k].exit, os.Interrupt)
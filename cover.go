@@ -0,0 +1,220 @@
+package realize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cover defines the coverage mode of the test tool
+type Cover struct {
+	Status    bool    `yaml:"status,omitempty" json:"status,omitempty"`
+	HTML      string  `yaml:"html,omitempty" json:"html,omitempty"`
+	Threshold float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+// Coverage merges the coverage profiles produced during a session
+type coverage struct {
+	sync.Mutex
+	mode   string
+	blocks map[string]block
+	last   map[string]float64
+}
+
+// Block of a coverage profile
+type block struct {
+	pkg   string
+	stmts int
+	count int
+}
+
+// Profile parses a coverage profile, blocks are indexed by position
+func profile(r io.Reader) (mode string, blocks map[string]block, err error) {
+	blocks = make(map[string]block)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			mode = strings.TrimSpace(line[len("mode:"):])
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		i := strings.LastIndex(fields[0], ":")
+		if i < 0 {
+			return "", nil, errors.New("invalid coverage block " + line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return "", nil, err
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return "", nil, err
+		}
+		b := blocks[fields[0]]
+		b.pkg = path.Dir(fields[0][:i])
+		b.stmts = stmts
+		b.count += count
+		blocks[fields[0]] = b
+	}
+	return mode, blocks, scanner.Err()
+}
+
+// Merge the blocks of a profile, the packages it contains are replaced
+func (c *coverage) merge(mode string, blocks map[string]block) (pkgs []string) {
+	if c.blocks == nil {
+		c.blocks = make(map[string]block)
+		c.last = make(map[string]float64)
+	}
+	c.mode = mode
+	seen := make(map[string]bool)
+	for _, b := range blocks {
+		if !seen[b.pkg] {
+			seen[b.pkg] = true
+			pkgs = append(pkgs, b.pkg)
+		}
+	}
+	for k, b := range c.blocks {
+		if seen[b.pkg] {
+			delete(c.blocks, k)
+		}
+	}
+	for k, b := range blocks {
+		c.blocks[k] = b
+	}
+	sort.Strings(pkgs)
+	return
+}
+
+// Percent of covered statements of a package, all packages if empty
+func (c *coverage) percent(pkg string) float64 {
+	var stmts, covered int
+	for _, b := range c.blocks {
+		if pkg != "" && b.pkg != pkg {
+			continue
+		}
+		stmts += b.stmts
+		if b.count > 0 {
+			covered += b.stmts
+		}
+	}
+	if stmts == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(stmts)
+}
+
+// Write the merged profile
+func (c *coverage) write(w io.Writer) error {
+	keys := make([]string, 0, len(c.blocks))
+	for k := range c.blocks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if _, err := fmt.Fprintln(w, "mode:", c.mode); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if _, err := fmt.Fprintln(w, k, c.blocks[k].stmts, c.blocks[k].count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delta formats the difference between two percentages
+func delta(prev, cur float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f%%)", cur-prev)
+}
+
+// Coverage reports the per package coverage of a test profile against the previous run
+func (p *Project) coverage(t *Tool, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		p.Err(err)
+		return
+	}
+	mode, blocks, err := profile(f)
+	f.Close()
+	if err != nil {
+		p.Err(err)
+		return
+	}
+	if p.cover == nil {
+		p.cover = &coverage{}
+	}
+	c := p.cover
+	c.Lock()
+	defer c.Unlock()
+	var lines []string
+	for _, pkg := range c.merge(mode, blocks) {
+		cur := c.percent(pkg)
+		prev, ok := c.last[pkg]
+		c.last[pkg] = cur
+		lines = append(lines, fmt.Sprintf("%s %.1f%%%s", pkg, cur, delta(prev, cur, ok)))
+	}
+	total := c.percent("")
+	prev, ok := c.last[""]
+	c.last[""] = total
+	text := fmt.Sprintf("coverage %.1f%%%s", total, delta(prev, total, ok))
+	cover := p.Tools.Test.Cover
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), Magenta.Regular(text))
+	out = BufferOut{Time: time.Now(), Text: text, Type: t.name, Stream: strings.Join(lines, "\n")}
+	p.stamp("log", out, msg, strings.Join(lines, "\n"))
+	if cover.HTML != "" {
+		if err := c.html(p.Path, cover.HTML); err != nil {
+			p.Err(err)
+		}
+		file := cover.HTML
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(p.Path, file)
+		}
+		p.track(file)
+	}
+	if cover.Threshold > 0 && total < cover.Threshold {
+		text = fmt.Sprintf("coverage %.1f%% dropped below threshold %.1f%%", total, cover.Threshold)
+		msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold(t.name), Red.Regular(text))
+		out = BufferOut{Time: time.Now(), Text: text, Type: t.name}
+		p.stamp("error", out, msg, "")
+	}
+}
+
+// Html writes the report of the merged profile
+func (c *coverage) html(dir, dst string) error {
+	f, err := ioutil.TempFile("", "realize-cover")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := c.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	if !filepath.IsAbs(dst) {
+		dst, _ = filepath.Abs(filepath.Join(dir, dst))
+	}
+	cmd := exec.Command("go", "tool", "cover", "-html="+f.Name(), "-o", dst)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(string(out) + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package realize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	a := `mode: set
+example.com/a/a.go:3.10,5.2 2 1
+example.com/a/a.go:6.10,8.2 2 0
+`
+	b := `mode: set
+example.com/b/b.go:3.10,5.2 4 1
+`
+	c := coverage{}
+	for _, v := range []string{a, b} {
+		mode, blocks, err := profile(strings.NewReader(v))
+		if err != nil || mode != "set" {
+			t.Fatal("Unexpected error", mode, err)
+		}
+		c.merge(mode, blocks)
+	}
+	if c.percent("example.com/a") != 50 || c.percent("example.com/b") != 100 {
+		t.Error("Unexpected coverage", c.percent("example.com/a"), c.percent("example.com/b"))
+	}
+	if total := c.percent(""); total != 75 {
+		t.Error("Unexpected total coverage", total)
+	}
+	// a package profile replaces the previous one
+	_, blocks, _ := profile(strings.NewReader("mode: set\nexample.com/a/a.go:3.10,5.2 2 1\n"))
+	if pkgs := c.merge("set", blocks); len(pkgs) != 1 || pkgs[0] != "example.com/a" {
+		t.Error("Unexpected packages", pkgs)
+	}
+	if c.percent("example.com/a") != 100 {
+		t.Error("Unexpected coverage", c.percent("example.com/a"))
+	}
+	var buf bytes.Buffer
+	if err := c.write(&buf); err != nil || !strings.HasPrefix(buf.String(), "mode: set\nexample.com/a/a.go:3.10,5.2 2 1\n") {
+		t.Error("Unexpected profile", buf.String(), err)
+	}
+	if delta(70, 75.5, true) != " (+5.5%)" || delta(0, 1, false) != "" {
+		t.Error("Unexpected delta")
+	}
+}
@@ -0,0 +1,59 @@
+package realize
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Debug runs the project executable under a headless delve server
+type Debug struct {
+	Status bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Listen string `yaml:"listen,omitempty" json:"listen,omitempty"`
+}
+
+// Gcflags disable the optimizations and the inlining of the debugged builds
+const gcflags = "-gcflags=all=-N -l"
+
+// Address of the delve server
+func (d Debug) address() string {
+	if d.Listen == "" {
+		return "127.0.0.1:2345"
+	}
+	return d.Listen
+}
+
+// Command returns the delve command running an executable
+func (d Debug) command(path string, args []string) (*exec.Cmd, error) {
+	dlv, err := exec.LookPath("dlv")
+	if err != nil {
+		return nil, errors.New("dlv not found, install it with go install github.com/go-delve/delve/cmd/dlv@latest")
+	}
+	dargs := []string{"exec", "--headless", "--listen=" + d.address(), "--api-version=2", "--accept-multiclient", "--continue", path}
+	if len(args) > 0 {
+		dargs = append(append(dargs, "--"), args...)
+	}
+	return exec.Command(dlv, dargs...), nil
+}
+
+// Debuggable reports the run options delve can't be used with
+func (p *Project) debuggable() error {
+	switch {
+	case len(p.Tools.Run.Sockets) > 0:
+		// the sockets would be passed to delve, not to the debugged process
+		return errors.New("debug can't be used with sockets")
+	case p.Tools.Run.Bluegreen.Status:
+		// both slots would listen on the same delve address
+		return errors.New("debug can't be used with bluegreen")
+	}
+	return nil
+}
+
+// Debugging prints the address the debugger can attach to
+func (p *Project) debugging() {
+	addr := p.Tools.Run.Debug.address()
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold("Debug"), "attach with", Magenta.Bold("dlv connect "+addr))
+	out = BufferOut{Time: time.Now(), Text: "Debug attach with dlv connect " + addr}
+	p.stamp("log", out, msg, "")
+}
@@ -0,0 +1,55 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDebug_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake dlv is a unix executable")
+	}
+	dir, err := ioutil.TempDir("", "debug_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+	d := Debug{Status: true}
+	if _, err := d.command("/app", nil); err == nil {
+		t.Error("Expected dlv not found")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "dlv"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := d.command("/app", []string{"-v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"exec", "--headless", "--listen=127.0.0.1:2345", "--api-version=2", "--accept-multiclient", "--continue", "/app", "--", "-v"}
+	if !reflect.DeepEqual(cmd.Args[1:], expected) {
+		t.Error("Unexpected delve arguments", cmd.Args)
+	}
+}
+
+func TestProject_Debuggable(t *testing.T) {
+	p := Project{}
+	p.Tools.Run.Debug.Status = true
+	if err := p.debuggable(); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	p.Tools.Run.Sockets = []string{":8080"}
+	if err := p.debuggable(); err == nil {
+		t.Error("Expected sockets rejected")
+	}
+	p.Tools.Run.Sockets = nil
+	p.Tools.Run.Bluegreen.Status = true
+	if err := p.debuggable(); err == nil {
+		t.Error("Expected bluegreen rejected")
+	}
+}
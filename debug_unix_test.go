@@ -0,0 +1,60 @@
+// +build !windows
+
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProject_RunDebugStop(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "debug_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	pidfile := filepath.Join(dir, "pid")
+	// like delve the fake one starts the debugged process in its own session and kills it when interrupted
+	// the trap is set before the pid is reported, when the test may interrupt it
+	dlv := "#!/bin/sh\ntrap 'kill $!; exit 0' INT\nsetsid sleep 60 > /dev/null 2>&1 &\necho $! > " + pidfile + ".tmp\nmv " + pidfile + ".tmp " + pidfile + "\nwait\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "dlv"), []byte(dlv), 0755); err != nil {
+		t.Fatal(err)
+	}
+	app := filepath.Join(dir, "app")
+	ioutil.WriteFile(app, []byte{}, 0755)
+	p := Project{parent: &Realize{}, Name: "test", process: &process{}}
+	p.Tools.Run.Debug.Status = true
+	stream, stop, done := make(chan Response, 100), make(chan bool), make(chan error)
+	go func() { done <- p.run(app, stream, stop) }()
+	var pid int
+	for i := 0; i < 100 && pid == 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+		content, _ := ioutil.ReadFile(pidfile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(content)))
+	}
+	if pid == 0 {
+		t.Fatal("Debugged process not started")
+	}
+	close(stop)
+	<-done
+	for i := 0; i < 40; i++ {
+		if syscall.Kill(pid, 0) != nil {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	syscall.Kill(pid, syscall.SIGKILL)
+	t.Error("Expected the debugged process stopped with delve")
+}
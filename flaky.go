@@ -0,0 +1,248 @@
+package realize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Flaky defines how flaky tests are detected and how the quarantined ones are run
+type Flaky struct {
+	Status  bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Mode    string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Retries int    `yaml:"retries,omitempty" json:"retries,omitempty"`
+}
+
+// Quarantined is a test detected as flaky
+type quarantined struct {
+	Package string    `yaml:"package" json:"package"`
+	Test    string    `yaml:"test" json:"test"`
+	Time    time.Time `yaml:"time" json:"time"`
+}
+
+// Run is the outcome of the tests of a package for a version of its code
+type run struct {
+	hash    string
+	actions map[string]string
+}
+
+// Fingerprint returns a hash of the go files of a package and of its dependencies,
+// the versioned modules are hashed by version and the local packages by content
+func fingerprint(dir string) string {
+	h := sha256.New()
+	contents(h, dir)
+	seen := map[string]bool{dir: true}
+	if abs, err := filepath.Abs(dir); err == nil {
+		seen[abs] = true
+	}
+	cmd := exec.Command("go", "list", "-e", "-deps", "-test", "-f", "{{if not .Standard}}{{.Dir}}\t{{with .Module}}{{if .Replace}}{{with .Replace}}{{if .Version}}{{.Path}}@{{.Version}}{{end}}{{end}}{{else if .Version}}{{.Path}}@{{.Version}}{{end}}{{end}}{{end}}", ".")
+	cmd.Dir = dir
+	out, _ := cmd.Output()
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 || fields[0] == "" || seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+		// local packages and replacements change without a new version
+		if fields[1] == "" {
+			contents(h, fields[0])
+			continue
+		}
+		fmt.Fprintln(h, fields[1])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Contents writes the go files of a directory to a hash
+func contents(h io.Writer, dir string) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s %d\n", file, len(content))
+		h.Write(content)
+	}
+}
+
+// Flips returns the tests whose outcome changed although the code didn't
+func (r run) flips(hash string, results []result) (tests []string) {
+	if r.hash != hash {
+		return
+	}
+	for _, res := range results {
+		if res.Test == "" || res.Action == "skip" {
+			continue
+		}
+		if prev, ok := r.actions[res.Test]; ok && prev != "skip" && prev != res.Action {
+			tests = append(tests, res.Test)
+		}
+	}
+	sort.Strings(tests)
+	return
+}
+
+// Quarantine file of the project
+func (p *Project) quarantine() string {
+	return filepath.Join(p.Path, FileFlaky)
+}
+
+// Load the quarantined tests saved in the project, the lock must be held,
+// an invalid file is reported once and never overwritten
+func (o *outcomes) load(file string) error {
+	if o.loaded {
+		return nil
+	}
+	o.loaded = true
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		err = yaml.Unmarshal(content, &o.flaky)
+	}
+	if err != nil {
+		o.invalid = true
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	return nil
+}
+
+// Quarantined returns the top level flaky tests of the package in a directory
+func (p *Project) quarantined(dir string) (tests []string) {
+	o := p.outcomes
+	o.Lock()
+	defer o.Unlock()
+	if err := o.load(p.quarantine()); err != nil {
+		p.Err(err)
+	}
+	pkg, ok := o.pkgs[dir]
+	if !ok {
+		cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return
+		}
+		pkg = strings.TrimSpace(string(out))
+		o.pkgs[dir] = pkg
+	}
+	seen := make(map[string]bool)
+	for _, q := range o.flaky {
+		name := strings.SplitN(q.Test, "/", 2)[0]
+		if q.Package == pkg && !seen[name] {
+			seen[name] = true
+			tests = append(tests, name)
+		}
+	}
+	sort.Strings(tests)
+	return
+}
+
+// Detect the tests flipping between runs of the same code and quarantine them
+func (p *Project) detect(t *Tool, dir string, hash string, results []result) {
+	o := p.outcomes
+	o.Lock()
+	lerr := o.load(p.quarantine())
+	prev := o.runs[dir]
+	flips := prev.flips(hash, results)
+	cur := run{hash: hash, actions: make(map[string]string)}
+	for _, r := range results {
+		if r.Test != "" {
+			cur.actions[r.Test] = r.Action
+			o.pkgs[dir] = r.Package
+		}
+	}
+	o.runs[dir] = cur
+	var added []string
+	for _, test := range flips {
+		known := false
+		for _, q := range o.flaky {
+			if q.Package == o.pkgs[dir] && q.Test == test {
+				known = true
+				break
+			}
+		}
+		if !known {
+			o.flaky = append(o.flaky, quarantined{Package: o.pkgs[dir], Test: test, Time: time.Now()})
+			added = append(added, test)
+		}
+	}
+	var err error
+	if len(added) > 0 && !o.invalid {
+		var content []byte
+		if content, err = yaml.Marshal(o.flaky); err == nil {
+			p.wrote(p.quarantine(), content)
+			err = ioutil.WriteFile(p.quarantine(), content, Permission)
+		}
+	}
+	o.Unlock()
+	if lerr != nil {
+		p.Err(lerr)
+	}
+	if err != nil {
+		p.Err(err)
+	}
+	p.report(t, dir, "flaky, quarantined after changing outcome without code changes", added, true)
+}
+
+// Retry the failing quarantined tests, they are reported as passed if a retry succeeds
+func (p *Project) retry(t *Tool, dir string, args []string, results []result, stop <-chan bool) ([]result, bool) {
+	flaky := p.quarantined(dir)
+	retries := p.Tools.Test.Flaky.Retries
+	if retries <= 0 {
+		retries = 2
+	}
+	var retry []string
+	for _, test := range failed(results) {
+		if contains(flaky, test) {
+			retry = append(retry, test)
+		}
+	}
+	if len(retry) == 0 {
+		return results, true
+	}
+	for i := 1; i <= retries; i++ {
+		again, _, ok, err := t.tests(dir, append(args, "-run", pattern(retry)), stop)
+		if !ok {
+			return results, false
+		}
+		if err == nil {
+			p.report(t, dir, fmt.Sprintf("flaky, passed on retry %d", i), retry, false)
+			var kept []result
+			for _, r := range results {
+				name := strings.SplitN(r.Test, "/", 2)[0]
+				if r.Test == "" && r.Action == "fail" || contains(retry, name) {
+					continue
+				}
+				kept = append(kept, r)
+			}
+			return append(kept, again...), true
+		}
+	}
+	p.report(t, dir, fmt.Sprintf("flaky, still failing after %d retries", retries), retry, true)
+	return results, true
+}
+
+// Contains reports whether a list contains a value
+func contains(list []string, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRun_Flips(t *testing.T) {
+	r := run{hash: "a", actions: map[string]string{"TestA": "pass", "TestB": "fail", "TestC": "skip"}}
+	results := []result{
+		{Test: "TestA", Action: "fail"},
+		{Test: "TestB", Action: "fail"},
+		{Test: "TestC", Action: "fail"},
+		{Action: "fail"},
+	}
+	if flips := r.flips("a", results); !reflect.DeepEqual(flips, []string{"TestA"}) {
+		t.Error("Unexpected flips", flips)
+	}
+	if flips := r.flips("b", results); len(flips) != 0 {
+		t.Error("Unexpected flips", flips)
+	}
+}
+
+func TestProject_Detect(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.go": "package a\n"})
+	defer os.RemoveAll(dir)
+	hash := fingerprint(dir)
+	if hash == fingerprint(os.TempDir()) {
+		t.Error("Unexpected fingerprint")
+	}
+	r := Realize{}
+	p := Project{parent: &r, Path: dir}
+	p.outcomes = &outcomes{runs: make(map[string]run), pkgs: make(map[string]string)}
+	tool := Tool{name: "Test"}
+	p.detect(&tool, dir, hash, []result{{Package: "example.com/a", Test: "TestA", Action: "pass"}})
+	p.detect(&tool, dir, hash, []result{{Package: "example.com/a", Test: "TestA", Action: "fail"}})
+	if tests := p.quarantined(dir); !reflect.DeepEqual(tests, []string{"TestA"}) {
+		t.Error("Unexpected quarantined tests", tests)
+	}
+	// the quarantine is saved in the project
+	p.outcomes = &outcomes{runs: make(map[string]run), pkgs: map[string]string{dir: "example.com/a"}}
+	if tests := p.quarantined(dir); !reflect.DeepEqual(tests, []string{"TestA"}) {
+		t.Error("Unexpected quarantined tests", tests)
+	}
+}
+
+func TestFingerprint_Dependencies(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":      "module example.com/m\n",
+		"a/a.go":      "package a\n\nimport \"example.com/m/b\"\n\nvar A = b.B\n",
+		"b/b.go":      "package b\n\nconst B = 1\n",
+		"c/c.go":      "package c\n",
+		"a/a_test.go": "package a\n\nimport (\n\t\"testing\"\n\n\t_ \"example.com/m/c\"\n)\n\nfunc TestA(t *testing.T) {}\n",
+	})
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a")
+	hash := fingerprint(a)
+	if hash != fingerprint(a) {
+		t.Fatal("Expected a stable fingerprint")
+	}
+	// an imported package changes the outcome of the tests
+	ioutil.WriteFile(filepath.Join(dir, "b", "b.go"), []byte("package b\n\nconst B = 2\n"), Permission)
+	changed := fingerprint(a)
+	if changed == hash {
+		t.Error("Expected the fingerprint to change with a dependency")
+	}
+	// and a package imported by the tests only
+	ioutil.WriteFile(filepath.Join(dir, "c", "c.go"), []byte("package c\n\nconst C = 1\n"), Permission)
+	if fingerprint(a) == changed {
+		t.Error("Expected the fingerprint to change with a test dependency")
+	}
+}
+
+func TestOutcomes_LoadInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaky_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, FileFlaky)
+	ioutil.WriteFile(file, []byte("- package: [\n"), Permission)
+	reported := 0
+	r := Realize{}
+	r.Err = func(c Context) { reported++ }
+	p := Project{parent: &r, Path: dir}
+	p.outcomes = &outcomes{runs: make(map[string]run), pkgs: make(map[string]string)}
+	tool := Tool{name: "Test"}
+	p.detect(&tool, dir, "h", []result{{Package: "example.com/a", Test: "TestA", Action: "pass"}})
+	p.detect(&tool, dir, "h", []result{{Package: "example.com/a", Test: "TestA", Action: "fail"}})
+	if reported == 0 {
+		t.Error("Expected the invalid file to be reported")
+	}
+	// the invalid file isn't overwritten
+	if content, _ := ioutil.ReadFile(file); string(content) != "- package: [\n" {
+		t.Error("Unexpected quarantine file", string(content))
+	}
+}
@@ -0,0 +1,72 @@
+package realize
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Imports defines goimports as formatter, local is the prefix of the imports grouped after the third party ones
+type Imports struct {
+	Status bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Local  string `yaml:"local,omitempty" json:"local,omitempty"`
+}
+
+// Format runs the formatter in check or fix mode, the file is never written by the formatter itself
+func (p *Project) format(t *Tool, dir string, args []string, stop <-chan bool) (response Response, ok bool) {
+	response.Name = t.name
+	file := args[len(args)-1]
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	var flags []string
+	for _, arg := range args[:len(args)-1] {
+		if arg != "-w" && arg != "-l" {
+			flags = append(flags, arg)
+		}
+	}
+	diff, stderr, ok, err := t.command(dir, append(flags, "-d", file), stop)
+	if !ok {
+		return Response{}, false
+	}
+	// recent formatters exit with an error when there is a diff
+	if err != nil && (diff == "" || stderr != "") {
+		response.Err = errors.New(stderr + diff + err.Error())
+		return response, true
+	}
+	if diff == "" {
+		return response, true
+	}
+	if p.Tools.Fmt.Mode != "fix" {
+		msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold(t.name), Red.Regular("not formatted"), ":", Magenta.Bold(file))
+		out = BufferOut{Time: time.Now(), Text: "not formatted", Path: file, Type: t.name, Stream: diff}
+		p.stamp("error", out, msg, diff)
+		return response, true
+	}
+	// formatted source, written here to know the content of the change event
+	formatted, stderr, ok, err := t.command(dir, append(flags, file), stop)
+	if !ok {
+		return Response{}, false
+	}
+	if err != nil {
+		response.Err = errors.New(stderr + err.Error())
+		return response, true
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		response.Err = err
+		return response, true
+	}
+	p.wrote(file, []byte(formatted))
+	if err := ioutil.WriteFile(file, []byte(formatted), fi.Mode()); err != nil {
+		response.Err = err
+		return response, true
+	}
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), Green.Regular("fixed"), ":", Magenta.Bold(file))
+	out = BufferOut{Time: time.Now(), Text: "fixed", Path: file, Type: t.name, Stream: diff}
+	p.stamp("log", out, msg, diff)
+	return response, true
+}
@@ -0,0 +1,51 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProject_Format(t *testing.T) {
+	dir, err := ioutil.TempDir("", "format_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.go")
+	source := "package a\nfunc  A() {}\n"
+	if err := ioutil.WriteFile(file, []byte(source), Permission); err != nil {
+		t.Fatal(err)
+	}
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", written: &writes{files: make(map[string]write)}}
+	p.Tools.Fmt = FmtTool{Tool: Tool{Status: true}, Mode: "check"}
+	p.Tools.Setup()
+	tool := p.Tools.Fmt.Tool
+	if !tool.diff || contains(tool.Args, "-w") {
+		t.Fatal("Unexpected setup", tool.Args)
+	}
+	response, ok := p.format(&tool, dir, append(tool.Args, file), nil)
+	if response.Err != nil || !ok {
+		t.Fatal("Unexpected response", response)
+	}
+	if content, _ := ioutil.ReadFile(file); string(content) != source {
+		t.Error("Unexpected write in check mode", string(content))
+	}
+	p.Tools.Fmt.Mode = "fix"
+	response, ok = p.format(&tool, dir, append(tool.Args, file), nil)
+	if response.Err != nil || !ok {
+		t.Fatal("Unexpected response", response)
+	}
+	if content, _ := ioutil.ReadFile(file); string(content) != "package a\n\nfunc A() {}\n" {
+		t.Error("Unexpected fix", string(content))
+	}
+	if !p.own(file) {
+		t.Error("Expected own write")
+	}
+	ioutil.WriteFile(file, []byte(source), Permission)
+	if p.own(file) || len(p.written.files) != 0 {
+		t.Error("Unexpected own write")
+	}
+}
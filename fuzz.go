@@ -0,0 +1,163 @@
+package realize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fuzz defines the targets supervised by the fuzz tool
+type Fuzz struct {
+	Targets []Fuzzer `yaml:"targets,omitempty" json:"targets,omitempty"`
+	Time    string   `yaml:"time,omitempty" json:"time,omitempty"`
+}
+
+// Fuzzer is a fuzz target of a package
+type Fuzzer struct {
+	Package string `yaml:"package" json:"package"`
+	Name    string `yaml:"name" json:"name"`
+}
+
+// Fuzzers keeps the fuzz targets running in background
+type fuzzers struct {
+	sync.Mutex
+	running map[Fuzzer]chan bool
+}
+
+// Dir returns the absolute directory of the package of the target
+func (f Fuzzer) dir(base string) string {
+	dir, _ := filepath.Abs(filepath.Join(base, f.Package))
+	return dir
+}
+
+// Pkg returns the package pattern of the target relative to the project
+func (f Fuzzer) pkg() string {
+	pkg := filepath.ToSlash(filepath.Clean(f.Package))
+	if pkg == "." || filepath.IsAbs(f.Package) {
+		return pkg
+	}
+	return "./" + pkg
+}
+
+// Corpus returns the files of the corpus saved in testdata of a target
+func (f Fuzzer) corpus(base string) map[string]bool {
+	files := make(map[string]bool)
+	entries, _ := ioutil.ReadDir(filepath.Join(f.dir(base), "testdata", "fuzz", f.Name))
+	for _, e := range entries {
+		files[e.Name()] = true
+	}
+	return files
+}
+
+// Fuzz starts the fuzz targets of the package changed, all of them if path is empty
+func (p *Project) fuzz(path string) {
+	if !p.Tools.Fuzz.Status {
+		return
+	}
+	if p.fuzzing == nil {
+		p.fuzzing = &fuzzers{running: make(map[Fuzzer]chan bool)}
+	}
+	dir := path
+	if filepath.Ext(path) != "" {
+		dir = filepath.Dir(path)
+	}
+	f := p.fuzzing
+	f.Lock()
+	defer f.Unlock()
+	for _, target := range p.Tools.Fuzz.Fuzzing.Targets {
+		if path != "" && target.dir(p.Path) != dir {
+			continue
+		}
+		if stop, ok := f.running[target]; ok {
+			close(stop)
+		}
+		stop := make(chan bool)
+		f.running[target] = stop
+		go p.fuzzer(target, stop)
+	}
+}
+
+// Unfuzz stops all the fuzz targets
+func (p *Project) unfuzz() {
+	if p.fuzzing == nil {
+		return
+	}
+	f := p.fuzzing
+	f.Lock()
+	defer f.Unlock()
+	for target, stop := range f.running {
+		close(stop)
+		delete(f.running, target)
+	}
+}
+
+// Fuzzer runs a fuzz target until its time budget is over, a crasher is found or it's stopped
+func (p *Project) fuzzer(target Fuzzer, stop <-chan bool) {
+	t := p.Tools.Fuzz
+	name := t.name + " " + target.Name
+	args := append(append([]string{}, t.cmd...), "-run", "^$", "-fuzz", "^"+target.Name+"$")
+	if t.Fuzzing.Time != "" {
+		args = append(args, "-fuzztime", t.Fuzzing.Time)
+	}
+	args = append(args, t.Args...)
+	corpus := target.corpus(p.Path)
+	var out bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = target.dir(p.Path)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	group(cmd)
+	// the heaviest of the background tools
+	p.confine(cmd, true)
+	msg = fmt.Sprintln(p.pname(p.Name, 1), ":", Green.Regular(name), "started")
+	p.stamp("log", BufferOut{Time: time.Now(), Text: name + " started"}, msg, "")
+	if err := cmd.Start(); err != nil {
+		p.Err(err)
+		return
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var err error
+	select {
+	case <-stop:
+		// interrupt lets the fuzzing engine save its cache
+		interrupt(cmd)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			kill(cmd)
+			<-done
+		}
+		return
+	case err = <-done:
+	}
+	if err == nil {
+		msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(name), "completed")
+		p.stamp("log", BufferOut{Time: time.Now(), Text: name + " completed", Type: t.name}, msg, "")
+		return
+	}
+	var crashers []string
+	for file := range target.corpus(p.Path) {
+		if !corpus[file] {
+			crashers = append(crashers, file)
+		}
+	}
+	if len(crashers) == 0 {
+		r := Response{Name: name, Err: errors.New(out.String() + err.Error())}
+		r.print(time.Now(), p)
+		return
+	}
+	for _, file := range crashers {
+		path := filepath.Join(cmd.Dir, "testdata", "fuzz", target.Name, file)
+		repro := "go test -run=" + target.Name + "/" + file + " " + target.pkg()
+		msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold(name), Red.Regular("found a crasher"), ":", Magenta.Bold(path))
+		buff := BufferOut{Time: time.Now(), Text: "found a crasher", Path: path, Type: t.name, Stream: repro}
+		p.stamp("error", buff, msg, strings.TrimSpace(out.String())+"\n"+repro)
+	}
+}
@@ -0,0 +1,33 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFuzzer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := Fuzzer{Package: "parser", Name: "FuzzParse"}
+	if f.pkg() != "./parser" || (Fuzzer{Package: "."}).pkg() != "." {
+		t.Error("Unexpected package", f.pkg())
+	}
+	if len(f.corpus(dir)) != 0 {
+		t.Error("Unexpected corpus")
+	}
+	corpus := filepath.Join(dir, "parser", "testdata", "fuzz", "FuzzParse")
+	if err := os.MkdirAll(corpus, Permission); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(corpus, "abc"), []byte("go test fuzz v1"), Permission); err != nil {
+		t.Fatal(err)
+	}
+	if files := f.corpus(dir); !files["abc"] || len(files) != 1 {
+		t.Error("Unexpected corpus", files)
+	}
+}
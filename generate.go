@@ -0,0 +1,131 @@
+package realize
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generated matches the header of the generated go files
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// Directive is a go:generate line, inputs are the globs annotated with realize:inputs on the previous line
+type directive struct {
+	file   string
+	text   string
+	inputs []string
+}
+
+// Directives returns the go:generate lines of a package in the order go generate runs them
+func parseGenerate(dir string) (list []directive) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.Strings(files)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		var inputs []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), " \t\r")
+			switch {
+			case strings.HasPrefix(line, "//realize:inputs "):
+				inputs = strings.Fields(strings.TrimPrefix(line, "//realize:inputs "))
+				continue
+			case strings.HasPrefix(line, "//go:generate "):
+				d := directive{file: file, text: line, inputs: inputs}
+				if len(d.inputs) == 0 {
+					// without annotation a directive depends on its own file
+					d.inputs = []string{filepath.Base(file)}
+				}
+				list = append(list, d)
+			}
+			inputs = nil
+		}
+		f.Close()
+	}
+	return
+}
+
+// Matches reports whether a file relative to the package is an input of the directive
+func (d directive) matches(file string) bool {
+	for _, glob := range d.inputs {
+		if ok, _ := filepath.Match(filepath.FromSlash(glob), file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns the modification time and size of the files of a directory tree
+func snapshot(dir string) map[string]string {
+	files := make(map[string]string)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files[path] = info.ModTime().Format(time.RFC3339Nano) + " " + strconv.FormatInt(info.Size(), 10)
+		}
+		return nil
+	})
+	return files
+}
+
+// Generate runs the directives of a package whose inputs changed, the directives fed by their outputs follow
+func (p *Project) generate(t *Tool, dir string, file string, args []string, stop <-chan bool) (response Response, ok bool) {
+	response.Name = t.name
+	all := file == dir
+	changed := make(map[string]bool)
+	if !all {
+		content, err := ioutil.ReadFile(file)
+		if err != nil || generated.Match(content) {
+			// generator outputs don't trigger the generators
+			return response, true
+		}
+		rel, _ := filepath.Rel(dir, file)
+		changed[rel] = true
+	}
+	var output []string
+	for _, d := range parseGenerate(dir) {
+		run := all
+		for f := range changed {
+			run = run || d.matches(f)
+		}
+		if !run {
+			continue
+		}
+		before := snapshot(dir)
+		expr := "^" + regexp.QuoteMeta(d.text) + "$"
+		// the outputs are ignored by the watcher while the directive runs
+		done := p.writing(dir)
+		stdout, stderr, ok, err := t.command(dir, append(append([]string{}, args...), "-run", expr, d.file), stop)
+		for f, v := range snapshot(dir) {
+			if before[f] == v {
+				continue
+			}
+			// change events of the outputs are ignored
+			p.track(f)
+			rel, _ := filepath.Rel(dir, f)
+			changed[rel] = true
+		}
+		done()
+		if !ok {
+			return Response{}, false
+		}
+		if err != nil {
+			response.Err = errors.New(stderr + stdout + err.Error())
+			return response, true
+		}
+		output = append(output, stdout)
+	}
+	if t.Output {
+		response.Out = strings.Join(output, "")
+	}
+	return response, true
+}
@@ -0,0 +1,68 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProject_Generate(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod": "module example.com/a\n",
+		"in.txt": "input\n",
+		"a.go":   "package a\n\n//realize:inputs *.txt\n//go:generate sh -c \"printf '// Code generated by test. DO NOT EDIT.\\\\n\\\\npackage a\\\\n' > gen.go\"\n",
+		"b.go":   "package a\n\n//go:generate touch b.out\n",
+		"c.go":   "package a\n\n//realize:inputs gen.go\n//go:generate touch c.out\n",
+		"gen.go": "// Code generated by test. DO NOT EDIT.\n\npackage a\n",
+	})
+	defer os.RemoveAll(dir)
+	list := parseGenerate(dir)
+	if len(list) != 3 || list[0].inputs[0] != "*.txt" || list[1].inputs[0] != "b.go" || list[2].text != "//go:generate touch c.out" {
+		t.Fatal("Unexpected directives", list)
+	}
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", written: &writes{files: make(map[string]write)}}
+	p.Tools.Generate = Tool{Status: true}
+	p.Tools.Setup()
+	tool := p.Tools.Generate
+	// outputs don't trigger the generators
+	if response, ok := p.generate(&tool, dir, filepath.Join(dir, "gen.go"), nil, nil); response.Err != nil || !ok {
+		t.Fatal("Unexpected response", response)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.out")); err == nil {
+		t.Error("Unexpected generation from an output")
+	}
+	if response, ok := p.generate(&tool, dir, filepath.Join(dir, "in.txt"), nil, nil); response.Err != nil || !ok {
+		t.Fatal("Unexpected response", response)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.out")); err == nil {
+		t.Error("Unexpected directive run")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.out")); err != nil {
+		t.Error("Expected directive fed by an output run")
+	}
+	if !p.own(filepath.Join(dir, "gen.go")) {
+		t.Error("Expected output written by realize")
+	}
+}
+
+func TestProject_WalkGenerate(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod": "module example.com/a\n",
+		"a.go":   "package a\n\n//go:generate sh -c \"echo run >> runs.txt\"\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir, stop: make(chan bool), watcher: &recorder{paths: make(map[string]bool)}}
+	p.Watcher.Exts = []string{"go"}
+	p.Tools.Generate = Tool{Status: true}
+	p.Tools.Setup()
+	if err := filepath.Walk(dir, p.walk); err != nil {
+		t.Fatal(err)
+	}
+	// the directive runs with the directory, not again with its file
+	if content, _ := ioutil.ReadFile(filepath.Join(dir, "runs.txt")); string(content) != "run\n" {
+		t.Error("Unexpected runs", string(content))
+	}
+}
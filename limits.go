@@ -0,0 +1,73 @@
+package realize
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Limits of the processes started for a project, memory in MB is enforced for the whole project by cgroup v2 when available
+// and otherwise as address space of every process, cpu is in seconds, nice and ionice apply to background tools
+type Limits struct {
+	Memory int64  `yaml:"memory,omitempty" json:"memory,omitempty"`
+	CPU    int64  `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	Files  int64  `yaml:"files,omitempty" json:"files,omitempty"`
+	Nice   int    `yaml:"nice,omitempty" json:"nice,omitempty"`
+	Ionice string `yaml:"ionice,omitempty" json:"ionice,omitempty"`
+}
+
+// Script returns the shell commands applying the limits and the commands prefixed to the process,
+// the process doesn't start if a limit can't be applied
+func (l Limits) script(cgroup string, background bool) (script string, prefix [][]string) {
+	var cmds []string
+	if l.Memory > 0 {
+		// kilobytes
+		memory := "ulimit -v " + strconv.FormatInt(l.Memory<<10, 10)
+		if cgroup != "" {
+			// the group may not accept the process
+			memory = "{ echo $$ > " + quote(cgroup+"/cgroup.procs") + "; } 2>/dev/null || " + memory
+		}
+		cmds = append(cmds, memory)
+	}
+	if l.CPU > 0 {
+		cmds = append(cmds, "ulimit -t "+strconv.FormatInt(l.CPU, 10))
+	}
+	if l.Files > 0 {
+		cmds = append(cmds, "ulimit -n "+strconv.FormatInt(l.Files, 10))
+	}
+	if background {
+		if l.Nice != 0 {
+			prefix = append(prefix, []string{"nice", "-n", strconv.Itoa(l.Nice)})
+		}
+		switch l.Ionice {
+		case "":
+		case "idle":
+			prefix = append(prefix, []string{"ionice", "-c", "3"})
+		default:
+			prefix = append(prefix, []string{"ionice", "-c", "2", "-n", l.Ionice})
+		}
+	}
+	if len(cmds) == 0 {
+		return "", prefix
+	}
+	return strings.Join(cmds, " && ") + ` && exec "$0" "$@"`, prefix
+}
+
+// Quote a string for the shell
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// Confine applies the project limits to a command before it starts
+func (p *Project) confine(cmd *exec.Cmd, background bool) {
+	if p == nil {
+		return
+	}
+	script, prefix := p.Limits.script(p.cgroup, background)
+	if script == "" && len(prefix) == 0 {
+		return
+	}
+	if err := confine(cmd, script, prefix); err != nil {
+		p.Err(err)
+	}
+}
@@ -0,0 +1,54 @@
+// +build linux
+
+package realize
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unsafe characters in a cgroup name
+var unsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// Cgroup creates a cgroup v2 with a memory limit for the processes of a project
+func cgroup(name string, max int64) (string, error) {
+	content, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	current := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			current = line[3:]
+		}
+	}
+	if current == "" {
+		return "", errors.New("cgroup v2 not available")
+	}
+	// a group with processes can't delegate controllers, the project group is a sibling of the realize one
+	parent := filepath.Join("/sys/fs/cgroup", filepath.Dir(current))
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return "", errors.New("cgroup v2 not available")
+	}
+	dir := filepath.Join(parent, "realize-"+strconv.Itoa(os.Getpid())+"-"+unsafe.ReplaceAllString(name, "_"))
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(max, 10)), 0644); err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// Uncgroup removes the cgroup of a project
+func uncgroup(dir string) {
+	if dir != "" {
+		os.Remove(dir)
+	}
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package realize
+
+import "errors"
+
+// Cgroup isn't supported, limits are applied to every process
+func cgroup(name string, max int64) (string, error) {
+	return "", errors.New("cgroup v2 not available")
+}
+
+// Uncgroup does nothing
+func uncgroup(dir string) {}
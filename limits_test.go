@@ -0,0 +1,35 @@
+package realize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLimits_Script(t *testing.T) {
+	l := Limits{Memory: 512, CPU: 60, Files: 256, Nice: 10, Ionice: "idle"}
+	script, prefix := l.script("", false)
+	if script != `ulimit -v 524288 && ulimit -t 60 && ulimit -n 256 && exec "$0" "$@"` {
+		t.Error("Unexpected script", script)
+	}
+	if len(prefix) != 0 {
+		t.Error("Unexpected prefix of a foreground process", prefix)
+	}
+	script, prefix = l.script("/sys/fs/cgroup/realize", true)
+	if script != `{ echo $$ > '/sys/fs/cgroup/realize/cgroup.procs'; } 2>/dev/null || ulimit -v 524288 && ulimit -t 60 && ulimit -n 256 && exec "$0" "$@"` {
+		t.Error("Unexpected script with a cgroup", script)
+	}
+	if !reflect.DeepEqual(prefix, [][]string{{"nice", "-n", "10"}, {"ionice", "-c", "3"}}) {
+		t.Error("Unexpected prefix", prefix)
+	}
+	l = Limits{Ionice: "4"}
+	script, prefix = l.script("", true)
+	if script != "" || !reflect.DeepEqual(prefix, [][]string{{"ionice", "-c", "2", "-n", "4"}}) {
+		t.Error("Unexpected ionice", script, prefix)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if q := quote("it's"); q != `'it'\''s'` {
+		t.Error("Unexpected quote", q)
+	}
+}
@@ -0,0 +1,55 @@
+// +build !windows
+
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTool_CompileConfined(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	// the group doesn't accept the process, the memory falls back to the address space
+	p := &Project{parent: &r, Name: "test", cgroup: filepath.Join(dir, "missing"), Limits: Limits{Memory: 2048, Files: 64}}
+	tool := Tool{parent: p, name: "Build", cmd: []string{"sh", "-c", "echo $(ulimit -v) $(ulimit -n) > limits.txt"}}
+	if response := tool.Compile(dir, make(chan bool)); response.Err != nil {
+		t.Fatal(response.Err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "limits.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := strings.Fields(string(content)); len(fields) != 2 || fields[0] != "2097152" || fields[1] != "64" {
+		t.Error("Unexpected limits of the build", string(content))
+	}
+	// a limit that can't be applied stops the build
+	os.Remove(filepath.Join(dir, "limits.txt"))
+	p.Limits = Limits{Files: 1 << 40}
+	if response := tool.Compile(dir, make(chan bool)); response.Err == nil {
+		t.Error("Expected an error of the limits")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "limits.txt")); err == nil {
+		t.Error("Unexpected build run without its limits")
+	}
+}
+
+func TestProject_FuzzerConfined(t *testing.T) {
+	dir := fixture(t, nil)
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := &Project{parent: &r, Name: "test", Path: dir, Limits: Limits{Files: 64}}
+	p.Tools.Fuzz.name = "Fuzz"
+	p.Tools.Fuzz.cmd = []string{"sh", "-c", "ulimit -n > limits.txt", "sh"}
+	p.fuzzer(Fuzzer{Package: ".", Name: "FuzzA"}, make(chan bool))
+	if content, _ := ioutil.ReadFile(filepath.Join(dir, "limits.txt")); strings.TrimSpace(string(content)) != "64" {
+		t.Error("Unexpected limits of the fuzz target", string(content))
+	}
+}
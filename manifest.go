@@ -0,0 +1,144 @@
+package realize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Manifest file name inside the history directory
+const manifest = "manifest.json"
+
+// Record describes a build artifact kept in the history
+type record struct {
+	Path     string        `json:"path"`
+	Hash     string        `json:"hash"`
+	Size     int64         `json:"size"`
+	Duration time.Duration `json:"duration"`
+	Commit   string        `json:"commit,omitempty"`
+	Dirty    bool          `json:"dirty,omitempty"`
+	Version  string        `json:"version,omitempty"`
+	Time     time.Time     `json:"time"`
+}
+
+// History is the directory containing the kept artifacts
+func (p *Project) history() string {
+	return filepath.Join(p.output(), "history")
+}
+
+// Keep copies an artifact in the history and prunes the oldest ones
+func (p *Project) keep(path string, n int, r record) ([]record, error) {
+	dir := p.history()
+	if err := os.MkdirAll(dir, Permission); err != nil {
+		return nil, err
+	}
+	records, _ := manifests(dir)
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	stamp := r.Time.Format("20060102-150405") + fmt.Sprintf("-%03d", r.Time.Nanosecond()/int(time.Millisecond))
+	dst := filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+stamp+ext)
+	if err := copyFile(path, dst); err != nil {
+		return nil, err
+	}
+	r.Path = dst
+	records = append(records, r)
+	for len(records) > n {
+		os.Remove(records[0].Path)
+		records = records[1:]
+	}
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	p.wrote(filepath.Join(dir, manifest), content)
+	return records, ioutil.WriteFile(filepath.Join(dir, manifest), content, Permission)
+}
+
+// Manifests reads the records saved in a history directory
+func manifests(dir string) (records []record, err error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, manifest))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &records)
+	return
+}
+
+// Checksum returns the sha256 and the size of a file
+func checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// CopyFile copies a file preserving its mode
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Measure logs the size of an artifact compared to the previous build and keeps it in the history
+func (p *Project) measure(t *BuildTool, path string, elapsed time.Duration, m meta) {
+	hash, n, err := checksum(path)
+	if err != nil {
+		return
+	}
+	text := size(n)
+	if p.size > 0 {
+		delta := size(n - p.size)
+		if n >= p.size {
+			delta = "+" + delta
+		}
+		text += " (" + delta + ")"
+	}
+	p.size = n
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), "size", Magenta.Regular(text))
+	out = BufferOut{Time: time.Now(), Text: t.name + " size " + text}
+	p.stamp("log", out, msg, "")
+	if t.Keep <= 0 {
+		return
+	}
+	_, err = p.keep(path, t.Keep, record{
+		Hash:     hash,
+		Size:     n,
+		Duration: elapsed,
+		Commit:   m.commit,
+		Dirty:    m.dirty,
+		Version:  m.version,
+		Time:     m.date,
+	})
+	if err != nil {
+		p.Err(err)
+	}
+}
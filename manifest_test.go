@@ -0,0 +1,45 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProject_Keep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := Project{Output: dir}
+	bin := filepath.Join(dir, "app")
+	if err := ioutil.WriteFile(bin, []byte("binary"), Permission); err != nil {
+		t.Fatal(err)
+	}
+	hash, n, err := checksum(bin)
+	if err != nil || n != 6 || hash == "" {
+		t.Fatal("Unexpected checksum", hash, n, err)
+	}
+	start := time.Now()
+	var records []record
+	for i := 0; i < 3; i++ {
+		records, err = p.keep(bin, 2, record{Hash: hash, Size: n, Time: start.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(records) != 2 {
+		t.Fatal("Expected 2 records instead", len(records))
+	}
+	saved, err := manifests(p.history())
+	if err != nil || len(saved) != 2 || saved[1].Path != records[1].Path {
+		t.Error("Unexpected manifest", saved, err)
+	}
+	files, _ := ioutil.ReadDir(p.history())
+	if len(files) != 3 {
+		t.Error("Expected 2 artifacts and the manifest instead", len(files))
+	}
+}
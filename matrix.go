@@ -0,0 +1,87 @@
+package realize
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target of the build matrix
+type Target struct {
+	GOOS   string   `yaml:"goos" json:"goos"`
+	GOARCH string   `yaml:"goarch" json:"goarch"`
+	Tags   []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	CGO    *bool    `yaml:"cgo,omitempty" json:"cgo,omitempty"`
+}
+
+// String returns the name of the target
+func (t Target) String() string {
+	name := t.GOOS + "/" + t.GOARCH
+	if len(t.Tags) > 0 {
+		name += " " + strings.Join(t.Tags, ",")
+	}
+	return name
+}
+
+// Env returns the environment of the target
+func (t Target) env() []string {
+	env := []string{"GOOS=" + t.GOOS, "GOARCH=" + t.GOARCH}
+	if t.CGO != nil {
+		cgo := "0"
+		if *t.CGO {
+			cgo = "1"
+		}
+		env = append(env, "CGO_ENABLED="+cgo)
+	}
+	return env
+}
+
+// Dir returns the output directory of the target
+func (t Target) dir() string {
+	dir := t.GOOS + "_" + t.GOARCH
+	for _, tag := range t.Tags {
+		dir += "_" + tag
+	}
+	return dir
+}
+
+// Cross builds the main package for each target of the build matrix in parallel
+func (p *Project) cross(build BuildTool, bin binary, stop <-chan bool) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, target := range build.Matrix {
+		tool := build
+		tool.name = tool.name + " " + target.String()
+		tool.env = target.env()
+		var args []string
+		if len(target.Tags) > 0 {
+			args = append(args, "-tags", strings.Join(target.Tags, ","))
+		}
+		if bin.pkg != "" {
+			name := path.Base(bin.pkg)
+			if target.GOOS == "windows" {
+				name += RExtWin
+			}
+			args = append(args, "-o", filepath.Join(p.output(), target.dir(), name), bin.pkg)
+		} else {
+			args = append(args, "./...")
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			r := tool.Compile(p.Path, stop, args...)
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			mu.Lock()
+			r.print(start, p)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
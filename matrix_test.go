@@ -0,0 +1,25 @@
+package realize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	cgo := false
+	target := Target{GOOS: "linux", GOARCH: "arm64", Tags: []string{"netgo", "osusergo"}, CGO: &cgo}
+	if target.String() != "linux/arm64 netgo,osusergo" {
+		t.Error("Unexpected name", target.String())
+	}
+	if target.dir() != "linux_arm64_netgo_osusergo" {
+		t.Error("Unexpected dir", target.dir())
+	}
+	expected := []string{"GOOS=linux", "GOARCH=arm64", "CGO_ENABLED=0"}
+	if !reflect.DeepEqual(target.env(), expected) {
+		t.Error("Expected", expected, "instead", target.env())
+	}
+	target = Target{GOOS: "darwin", GOARCH: "amd64"}
+	if len(target.env()) != 2 {
+		t.Error("Unexpected env", target.env())
+	}
+}
@@ -0,0 +1,95 @@
+package realize
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Inject defines the build metadata set with -ldflags -X
+type Inject struct {
+	Status  bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// Meta describes the revision of a build
+type meta struct {
+	version string
+	commit  string
+	dirty   bool
+	date    time.Time
+}
+
+// Revision reads the build metadata of a directory from git
+func revision(dir string) (m meta) {
+	m.date = time.Now()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	m.commit = git("rev-parse", "HEAD")
+	if m.commit == "" {
+		return
+	}
+	m.version = git("describe", "--tags", "--always", "--dirty")
+	m.dirty = git("status", "--porcelain") != ""
+	return
+}
+
+// Flags returns the linker flags injecting the metadata
+func (i Inject) flags(m meta) string {
+	pkg := i.Package
+	if pkg == "" {
+		pkg = "main"
+	}
+	version := i.Version
+	if version == "" {
+		version = m.version
+	}
+	vars := [][2]string{
+		{"version", version},
+		{"commit", m.commit},
+		{"dirty", strconv.FormatBool(m.dirty)},
+		{"date", m.date.UTC().Format(time.RFC3339)},
+	}
+	var flags []string
+	for _, v := range vars {
+		if v[1] == "" {
+			continue
+		}
+		x := pkg + "." + v[0] + "=" + v[1]
+		if strings.ContainsAny(x, " \t") {
+			x = "'" + x + "'"
+		}
+		flags = append(flags, "-X", x)
+	}
+	return strings.Join(flags, " ")
+}
+
+// Ldflags merges linker flags into the -ldflags argument, if any
+func ldflags(args []string, flags string) []string {
+	if flags == "" {
+		return args
+	}
+	result := append([]string{}, args...)
+	for i, arg := range result {
+		switch {
+		case arg == "-ldflags" || arg == "--ldflags":
+			if i+1 < len(result) {
+				result[i+1] = strings.TrimSpace(result[i+1] + " " + flags)
+				return result
+			}
+		case strings.HasPrefix(arg, "-ldflags=") || strings.HasPrefix(arg, "--ldflags="):
+			result[i] = strings.TrimSpace(arg + " " + flags)
+			return result
+		}
+	}
+	return append(result, "-ldflags", flags)
+}
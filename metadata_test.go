@@ -0,0 +1,39 @@
+package realize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInject_Flags(t *testing.T) {
+	m := meta{version: "v1.0.0", commit: "abc", date: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	flags := Inject{}.flags(m)
+	for _, v := range []string{"-X main.version=v1.0.0", "-X main.commit=abc", "-X main.dirty=false", "-X main.date=2020-01-02T03:04:05Z"} {
+		if !strings.Contains(flags, v) {
+			t.Error("Expected", v, "in", flags)
+		}
+	}
+	flags = Inject{Package: "example.com/app/version", Version: "dev build"}.flags(meta{})
+	if !strings.Contains(flags, "'example.com/app/version.version=dev build'") || strings.Contains(flags, "commit") {
+		t.Error("Unexpected flags", flags)
+	}
+}
+
+func TestLdflags(t *testing.T) {
+	data := []struct {
+		args     []string
+		expected []string
+	}{
+		{nil, []string{"-ldflags", "-X a=b"}},
+		{[]string{"-v", "-ldflags", "-s"}, []string{"-v", "-ldflags", "-s -X a=b"}},
+		{[]string{"-ldflags=-w"}, []string{"-ldflags=-w -X a=b"}},
+	}
+	for _, v := range data {
+		result := ldflags(v.args, "-X a=b")
+		if !reflect.DeepEqual(result, v.expected) {
+			t.Error("Expected", v.expected, "instead", result)
+		}
+	}
+}
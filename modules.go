@@ -0,0 +1,91 @@
+package realize
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mod defines the commands run when the module files change, args are given by command like download, tidy or verify
+// and follow the args of the tool given to every command
+type Mod struct {
+	Tidy   bool                `yaml:"tidy,omitempty" json:"tidy,omitempty"`
+	Verify bool                `yaml:"verify,omitempty" json:"verify,omitempty"`
+	Flags  string              `yaml:"flags,omitempty" json:"flags,omitempty"`
+	Args   map[string][]string `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// Module reports whether a path is a go.mod, go.sum or go.work file
+func module(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "go.sum", "go.work":
+		return true
+	}
+	return false
+}
+
+// Packages lists the directories of the packages of a module
+func packages(dir string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.Dir}}", "./...")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(stderr.String() + err.Error())
+	}
+	return strings.Fields(stdout.String()), nil
+}
+
+// Modules downloads, tidies and verifies the module then runs the tools on all its packages
+func (p *Project) modules(stop <-chan bool) bool {
+	t := p.Tools.Mod
+	flags := t.Modules.Flags
+	if flags == "" {
+		flags = "-mod=mod"
+	}
+	cmds := []string{"download"}
+	if t.Modules.Tidy {
+		cmds = append(cmds, "tidy")
+	}
+	if t.Modules.Verify {
+		cmds = append(cmds, "verify")
+	}
+	for _, c := range cmds {
+		tool := t
+		tool.parent = p
+		tool.name = t.name + " " + c
+		tool.cmd = append(append([]string{}, t.cmd...), c)
+		// the commands don't accept the same flags
+		tool.Args = append(append([]string{}, t.Args...), t.Modules.Args[c]...)
+		tool.env = []string{"GOFLAGS=" + flags}
+		start := time.Now()
+		r := tool.Compile(p.Path, stop)
+		select {
+		case <-stop:
+			return false
+		default:
+		}
+		r.print(start, p)
+		if r.Err != nil {
+			return false
+		}
+	}
+	dirs, err := packages(p.Path)
+	if err != nil {
+		p.Err(err)
+		return false
+	}
+	for _, dir := range dirs {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			continue
+		}
+		p.tools(stop, dir, fi)
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package realize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModule(t *testing.T) {
+	paths := map[string]bool{
+		"/a/go.mod":   true,
+		"/a/go.sum":   true,
+		"go.work":     true,
+		"/a/b/go.go":  false,
+		"/a/mod.go":   false,
+		"/a/go.mod.x": false,
+	}
+	for i, v := range paths {
+		if module(i) != v {
+			t.Error("Unexpected result", i, v)
+		}
+	}
+}
+
+func TestPackages(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":     "module example.com/m\n",
+		"m.go":       "package m\n",
+		"sub/sub.go": "package sub\n",
+	})
+	defer os.RemoveAll(dir)
+	dirs, err := packages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || filepath.Base(dirs[0]) != filepath.Base(dir) || filepath.Base(dirs[1]) != "sub" {
+		t.Error("Unexpected packages", dirs)
+	}
+}
+
+func TestProject_ModulesArgs(t *testing.T) {
+	dir := fixture(t, map[string]string{"go.mod": "module example.com/m\n"})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Path: dir}
+	p.Tools.Mod.Status = true
+	p.Tools.Mod.Modules.Tidy = true
+	// -e is accepted by tidy only, download would fail with it
+	p.Tools.Mod.Modules.Args = map[string][]string{"tidy": {"-e"}}
+	// the args of the tool are given to every command
+	p.Tools.Mod.Args = []string{"-x"}
+	p.Tools.Setup()
+	if !p.modules(make(chan bool)) {
+		t.Error("Expected the args to be passed to tidy only")
+	}
+	p.Tools.Mod.Args = []string{"-unknown"}
+	if p.modules(make(chan bool)) {
+		t.Error("Expected the args of the tool passed to the commands")
+	}
+}
+
+func TestProject_ValidateModule(t *testing.T) {
+	r := Realize{}
+	p := Project{parent: &r, Watcher: Watch{Exts: []string{"go"}}}
+	if p.Validate("/a/go.mod", false) {
+		t.Error("Unexpected module file watched")
+	}
+	p.Tools.Mod.Status = true
+	if !p.Validate("/a/go.mod", false) || !p.Validate("/a/go.work", false) {
+		t.Error("Expected module file watched")
+	}
+}
@@ -0,0 +1,97 @@
+package realize
+
+import (
+	"fmt"
+	"time"
+)
+
+// Monitor samples the resources used by the run process tree, threshold is the memory growth warned in MB
+type Monitor struct {
+	Status    bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Interval  string `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Threshold int64  `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+// Usage of a process tree, ticks is the cpu time used
+type usage struct {
+	pid     int
+	time    time.Time
+	ticks   uint64
+	cpu     float64
+	rss     int64
+	fds     int
+	threads int
+	procs   int
+}
+
+// String formats the usage as a status line
+func (u usage) String() string {
+	return fmt.Sprintf("pid %d cpu %.1f%% rss %s fds %d threads %d processes %d", u.pid, u.cpu, size(u.rss), u.fds, u.threads, u.procs)
+}
+
+// Rate computes the cpu percentage since a previous sample of the same process
+func (u *usage) rate(prev usage, hz uint64) {
+	elapsed := u.time.Sub(prev.time).Seconds()
+	if prev.pid != u.pid || elapsed <= 0 || u.ticks < prev.ticks {
+		return
+	}
+	u.cpu = float64(u.ticks-prev.ticks) / float64(hz) / elapsed * 100
+}
+
+// Pid of the running executable
+func (p *Project) pid() int {
+	if p.process == nil {
+		return 0
+	}
+	p.process.Lock()
+	defer p.process.Unlock()
+	if p.process.cmd == nil || p.process.cmd.Process == nil {
+		return 0
+	}
+	return p.process.cmd.Process.Pid
+}
+
+// Monitor prints the usage of the running process periodically or on demand and warns about memory growth
+func (p *Project) monitor(stop <-chan bool) {
+	m := p.Tools.Run.Monitor
+	tick := time.NewTicker(duration(m.Interval, 10*time.Second))
+	defer tick.Stop()
+	demand := demand()
+	defer undemand(demand)
+	var prev, base usage
+	warned := false
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+		case <-demand:
+		}
+		pid := p.pid()
+		if pid == 0 {
+			continue
+		}
+		u, err := sample(pid)
+		if err != nil {
+			p.Err(err)
+			continue
+		}
+		u.rate(prev, hz)
+		prev = u
+		// a new process after a reload
+		if base.pid != pid {
+			base, warned = u, false
+		}
+		text := u.String()
+		msg = fmt.Sprintln(p.pname(p.Name, 3), ":", Blue.Bold("Monitor"), Blue.Regular(text))
+		out = BufferOut{Time: time.Now(), Text: text, Type: "Monitor"}
+		p.stamp("log", out, msg, "")
+		if growth := u.rss - base.rss; m.Threshold > 0 && !warned && growth > m.Threshold<<20 {
+			warned = true
+			text = fmt.Sprintf("memory grew by %s since the start to %s", size(growth), size(u.rss))
+			msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold("Monitor"), Red.Regular(text))
+			out = BufferOut{Time: time.Now(), Text: text, Type: "Monitor"}
+			p.stamp("error", out, msg, "")
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// +build linux
+
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Clock ticks per second of the cpu times in /proc
+const hz = 100
+
+// Stat reads the parent, cpu ticks, threads and resident pages of a process
+func stat(pid int) (ppid int, ticks uint64, threads int, pages int64, err error) {
+	content, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return
+	}
+	// the command name may contain spaces
+	s := string(content)
+	fields := strings.Fields(s[strings.LastIndex(s, ")")+1:])
+	if len(fields) < 22 {
+		return 0, 0, 0, 0, syscall.EINVAL
+	}
+	ppid, _ = strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	threads, _ = strconv.Atoi(fields[17])
+	pages, _ = strconv.ParseInt(fields[21], 10, 64)
+	return ppid, utime + stime, threads, pages, nil
+}
+
+// Descendants returns a process and its descendants
+func descendants(pid int) []int {
+	children := make(map[int][]int)
+	dirs, _ := ioutil.ReadDir("/proc")
+	for _, d := range dirs {
+		child, err := strconv.Atoi(d.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, _, _, _, err := stat(child); err == nil {
+			children[ppid] = append(children[ppid], child)
+		}
+	}
+	pids := []int{pid}
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, children[pids[i]]...)
+	}
+	return pids
+}
+
+// Sample the usage of a process tree from /proc
+func sample(pid int) (usage, error) {
+	u := usage{pid: pid, time: time.Now()}
+	page := int64(os.Getpagesize())
+	for i, p := range descendants(pid) {
+		_, ticks, threads, pages, err := stat(p)
+		if err != nil {
+			if i == 0 {
+				return u, err
+			}
+			// exited meanwhile
+			continue
+		}
+		fds, _ := ioutil.ReadDir(filepath.Join("/proc", strconv.Itoa(p), "fd"))
+		u.ticks += ticks
+		u.threads += threads
+		u.rss += pages * page
+		u.fds += len(fds)
+		u.procs++
+	}
+	return u, nil
+}
+
+// Demand returns the channel of the on demand status requests, sent with SIGUSR1
+func demand() chan os.Signal {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	return c
+}
+
+// Undemand stops the on demand status requests
+func undemand(c chan os.Signal) {
+	signal.Stop(c)
+}
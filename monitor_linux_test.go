@@ -0,0 +1,33 @@
+// +build linux
+
+package realize
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	u, err := sample(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.procs < 2 {
+		t.Fatal("Expected the child process in the tree", u.procs)
+	}
+	if u.rss <= 0 || u.threads <= 0 || u.fds <= 0 {
+		t.Fatal("Unexpected usage", u)
+	}
+	if _, err := sample(-1); err == nil {
+		t.Fatal("Expected an error for a missing process")
+	}
+}
@@ -0,0 +1,24 @@
+// +build !linux
+
+package realize
+
+import (
+	"errors"
+	"os"
+)
+
+// Clock ticks per second of the cpu times
+const hz = 100
+
+// Sample isn't supported without /proc
+func sample(pid int) (usage, error) {
+	return usage{}, errors.New("resource monitor supported only on linux")
+}
+
+// Demand returns a channel never receiving, status requests aren't supported
+func demand() chan os.Signal {
+	return nil
+}
+
+// Undemand does nothing
+func undemand(c chan os.Signal) {}
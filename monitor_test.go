@@ -0,0 +1,33 @@
+package realize
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsage_Rate(t *testing.T) {
+	now := time.Now()
+	prev := usage{pid: 1, time: now, ticks: 100}
+	u := usage{pid: 1, time: now.Add(2 * time.Second), ticks: 200}
+	u.rate(prev, 100)
+	if u.cpu != 50 {
+		t.Fatal("Unexpected cpu", u.cpu)
+	}
+	// another process
+	u = usage{pid: 2, time: now.Add(2 * time.Second), ticks: 200}
+	u.rate(prev, 100)
+	if u.cpu != 0 {
+		t.Fatal("Unexpected cpu of a new process", u.cpu)
+	}
+}
+
+func TestUsage_String(t *testing.T) {
+	u := usage{pid: 10, cpu: 12.5, rss: 3 << 20, fds: 7, threads: 4, procs: 2}
+	s := u.String()
+	for _, part := range []string{"pid 10", "cpu 12.5%", "rss 3.0 MB", "fds 7", "threads 4", "processes 2"} {
+		if !strings.Contains(s, part) {
+			t.Fatal("Expected", part, "in", s)
+		}
+	}
+}
@@ -27,11 +27,14 @@ var (
 
 // Watch info
 type Watch struct {
-	Exts    []string  `yaml:"extensions" json:"extensions"`
-	Paths   []string  `yaml:"paths" json:"paths"`
-	Scripts []Command `yaml:"scripts,omitempty" json:"scripts,omitempty"`
-	Hidden  bool      `yaml:"hidden,omitempty" json:"hidden,omitempty"`
-	Ignore  []string  `yaml:"ignored_paths,omitempty" json:"ignored_paths,omitempty"`
+	Exts     []string  `yaml:"extensions" json:"extensions"`
+	Paths    []string  `yaml:"paths" json:"paths"`
+	Scripts  []Command `yaml:"scripts,omitempty" json:"scripts,omitempty"`
+	Hidden   bool      `yaml:"hidden,omitempty" json:"hidden,omitempty"`
+	Ignore   []string  `yaml:"ignored_paths,omitempty" json:"ignored_paths,omitempty"`
+	Semantic bool      `yaml:"semantic,omitempty" json:"semantic,omitempty"` //skip the builds of comment only changes
+	Rules    []Rule    `yaml:"rules,omitempty" json:"rules,omitempty"`
+	OnBusy   string    `yaml:"on_busy,omitempty" json:"on_busy,omitempty"`
 }
 
 type Ignore struct {
@@ -42,6 +45,7 @@ type Ignore struct {
 // Command fields
 type Command struct {
 	Cmd    string `yaml:"command" json:"command"`
+	Name   string `yaml:"name,omitempty" json:"name,omitempty"`
 	Type   string `yaml:"type" json:"type"`
 	Path   string `yaml:"path,omitempty" json:"path,omitempty"`
 	Global bool   `yaml:"global,omitempty" json:"global,omitempty"`
@@ -58,6 +62,20 @@ type Project struct {
 	last       last
 	files      int64
 	folders    int64
+	size       int64
+	cover      *coverage
+	bench      *benchmarks
+	fuzzing    *fuzzers
+	outcomes   *outcomes
+	linked     *linked
+	written    *writes
+	sources    *sources
+	process    *process
+	deploy     *deployment
+	sockets    *sockets
+	good       *good
+	cgroup     string
+	busy       *busy
 	init       bool
 	Name       string            `yaml:"name" json:"name"`
 	Path       string            `yaml:"path" json:"path"`
@@ -67,6 +85,8 @@ type Project struct {
 	Watcher    Watch             `yaml:"watcher" json:"watcher"`
 	Buffer     Buffer            `yaml:"-" json:"buffer"`
 	ErrPattern string            `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Output     string            `yaml:"output,omitempty" json:"output,omitempty"`
+	Limits     Limits            `yaml:"limits,omitempty" json:"limits,omitempty"`
 }
 
 // Last is used to save info about last file changed
@@ -129,6 +149,12 @@ func (p *Project) Before() {
 			}
 		}
 	}
+	// local modules used by the project
+	p.workspace()
+	// tests summary
+	p.summary()
+	// background fuzzing
+	p.fuzz("")
 	// start message
 	msg = fmt.Sprintln(p.pname(p.Name, 1), ":", Blue.Bold("Watching"), Magenta.Bold(p.files), "file/s", Magenta.Bold(p.folders), "folder/s")
 	out = BufferOut{Time: time.Now(), Text: "Watching " + strconv.FormatInt(p.files, 10) + " files/s " + strconv.FormatInt(p.folders, 10) + " folder/s"}
@@ -150,6 +176,7 @@ func (p *Project) Err(err error) {
 
 // Change event message
 func (p *Project) Change(event fsnotify.Event) {
+	p.focus()
 	if p.parent.Change != nil {
 		p.parent.Change(Context{Project: p, Event: event})
 		return
@@ -172,6 +199,7 @@ func (p *Project) Reload(path string, stop <-chan bool) {
 		return
 	}
 	var done bool
+	var bin binary
 	var install, build Response
 	go func() {
 		for {
@@ -190,8 +218,34 @@ func (p *Project) Reload(path string, stop <-chan bool) {
 	if done {
 		return
 	}
+	// replace and use directives may have changed
+	if base, _ := filepath.Abs(p.Path); module(path) && (filepath.Dir(path) == base || p.workfile(path)) {
+		p.workspace()
+	}
 	// Go supported tools
-	if len(path) > 0 {
+	if len(path) > 0 && p.Tools.Mod.Status && module(path) {
+		// module files affect all the packages
+		if !p.modules(stop) {
+			return
+		}
+		p.summary()
+		p.fuzz("")
+	} else if l, ok := p.local(path); ok {
+		// local modules affect the packages importing them
+		dirs, err := p.dependents(l, path)
+		if err != nil {
+			p.Err(err)
+		}
+		for _, dir := range dirs {
+			fi, err := os.Stat(dir)
+			if err != nil {
+				continue
+			}
+			p.tools(stop, dir, fi)
+			p.fuzz(dir)
+		}
+		p.summary()
+	} else if len(path) > 0 {
 		fi, err := os.Stat(path)
 		if filepath.Ext(path) == "" {
 			fi, err = os.Stat(path)
@@ -200,23 +254,58 @@ func (p *Project) Reload(path string, stop <-chan bool) {
 			p.Err(err)
 		}
 		p.tools(stop, path, fi)
+		if err == nil && !fi.IsDir() && filepath.Ext(path) == ".go" {
+			// the package tools run with the changed file too
+			p.pkg(path, stop, "Test", "Bench")
+		}
+		// tests summary
+		p.summary()
+		// restart the fuzz targets of the package
+		p.fuzz(path)
 	}
 	// Prevent fake events on polling startup
 	p.init = true
-	// prevent errors using realize without config with only run flag
-	if p.Tools.Run.Status && !p.Tools.Install.Status && !p.Tools.Build.Status {
-		p.Tools.Install.Status = true
-	}
 	if done {
 		return
 	}
+	// main package
+	if p.Tools.Install.Status || p.Tools.Build.Status {
+		var err error
+		if bin, err = p.binary(); err != nil && p.Tools.Run.Status {
+			p.Err(err)
+		}
+		// the running artifact isn't overwritten
+		if p.deploy != nil {
+			bin.slot = p.next()
+		}
+	}
+	// build metadata
+	var m meta
+	if p.Tools.Install.Inject.Status || p.Tools.Build.Inject.Status || p.Tools.Install.Keep > 0 || p.Tools.Build.Keep > 0 {
+		m = revision(p.Path)
+	}
 	if p.Tools.Install.Status {
 		msg = fmt.Sprintln(p.pname(p.Name, 1), ":", Green.Regular(p.Tools.Install.name), "started")
 		out = BufferOut{Time: time.Now(), Text: p.Tools.Install.name + " started"}
 		p.stamp("log", out, msg, "")
 		start := time.Now()
-		install = p.Tools.Install.Compile(p.Path, stop)
+		tool := p.Tools.Install
+		tool.parent = p
+		if tool.Inject.Status {
+			tool.Args = ldflags(tool.Args, tool.Inject.flags(m))
+		}
+		if p.Tools.Run.Debug.Status {
+			tool.Args = append(append([]string{}, tool.Args...), gcflags)
+		}
+		var args []string
+		if bin.pkg != "" {
+			args = append(args, bin.pkg)
+		}
+		install = tool.Compile(p.Path, stop, args...)
 		install.print(start, p)
+		if install.Err == nil && bin.target != "" && !p.Tools.Build.Status {
+			p.measure(&tool, bin.target, time.Since(start), m)
+		}
 	}
 	if done {
 		return
@@ -226,42 +315,44 @@ func (p *Project) Reload(path string, stop <-chan bool) {
 		out = BufferOut{Time: time.Now(), Text: p.Tools.Build.name + " started"}
 		p.stamp("log", out, msg, "")
 		start := time.Now()
-		build = p.Tools.Build.Compile(p.Path, stop)
+		tool := p.Tools.Build
+		tool.parent = p
+		if tool.Inject.Status {
+			tool.Args = ldflags(tool.Args, tool.Inject.flags(m))
+		}
+		if p.Tools.Run.Debug.Status {
+			tool.Args = append(append([]string{}, tool.Args...), gcflags)
+		}
+		var args []string
+		if bin.pkg != "" {
+			if err := os.MkdirAll(p.output(), Permission); err != nil {
+				p.Err(err)
+			}
+			args = append(args, "-o", p.artifact(bin), bin.pkg)
+		}
+		build = tool.Compile(p.Path, stop, args...)
 		build.print(start, p)
+		if build.Err == nil && bin.pkg != "" {
+			p.measure(&tool, p.artifact(bin), time.Since(start), m)
+		}
+		// cross compilation
+		if len(tool.Matrix) > 0 {
+			go p.cross(tool, bin, stop)
+		}
 	}
 	if done {
 		return
 	}
 	if install.Err == nil && build.Err == nil && p.Tools.Run.Status {
-		result := make(chan Response)
-		go func() {
-			for {
-				select {
-				case <-stop:
-					return
-				case r := <-result:
-					if r.Err != nil {
-						msg := fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Regular(r.Err))
-						out := BufferOut{Time: time.Now(), Text: r.Err.Error(), Type: "Go Run"}
-						p.stamp("error", out, msg, "")
-					}
-					if r.Out != "" {
-						msg := fmt.Sprintln(p.pname(p.Name, 3), ":", Blue.Regular(r.Out))
-						out := BufferOut{Time: time.Now(), Text: r.Out, Type: "Go Run"}
-						p.stamp("out", out, msg, "")
-					}
-				}
-			}
-		}()
-		go func() {
-			log.Println(p.pname(p.Name, 1), ":", "Running..")
-			err := p.run(p.Path, result, stop)
-			if err != nil {
-				msg := fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Regular(err))
-				out := BufferOut{Time: time.Now(), Text: err.Error(), Type: "Go Run"}
-				p.stamp("error", out, msg, "")
-			}
-		}()
+		p.succeeded(bin)
+		if p.deploy != nil {
+			p.swap(bin, stop)
+		} else {
+			p.launch(bin, stop)
+		}
+	} else if p.Tools.Run.Status {
+		// failed build
+		p.stale(stop)
 	}
 	if done {
 		return
@@ -269,24 +360,107 @@ func (p *Project) Reload(path string, stop <-chan bool) {
 	p.cmd(stop, "after", false)
 }
 
+// Launch runs the project executable until stop is closed, exited is closed when it ends
+func (p *Project) launch(bin binary, stop <-chan bool, env ...string) (exited <-chan bool) {
+	done := make(chan bool)
+	result := make(chan Response)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case r := <-result:
+				if r.Err != nil {
+					msg := fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Regular(r.Err))
+					out := BufferOut{Time: time.Now(), Text: r.Err.Error(), Type: "Go Run"}
+					p.stamp("error", out, msg, "")
+				}
+				if r.Out != "" {
+					msg := fmt.Sprintln(p.pname(p.Name, 3), ":", Blue.Regular(r.Out))
+					out := BufferOut{Time: time.Now(), Text: r.Out, Type: "Go Run"}
+					p.stamp("out", out, msg, "")
+				}
+			}
+		}
+	}()
+	go func() {
+		defer close(done)
+		log.Println(p.pname(p.Name, 1), ":", "Running..")
+		err := p.run(p.executable(bin), result, stop, env...)
+		if err != nil {
+			msg := fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Regular(err))
+			out := BufferOut{Time: time.Now(), Text: err.Error(), Type: "Go Run"}
+			p.stamp("error", out, msg, "")
+		}
+	}()
+	return done
+}
+
 // Watch a project
 func (p *Project) Watch(wg *sync.WaitGroup) {
 	var err error
 	// change channel
 	p.stop = make(chan bool)
+	// files written by realize
+	p.written = &writes{files: make(map[string]write)}
+	// running executable
+	p.process = &process{}
+	// modules outside the project
+	p.linked = &linked{}
+	// blue/green processes outlive the changes
+	if p.Tools.Run.Bluegreen.Status {
+		p.deploy = &deployment{}
+	}
+	// last successful build
+	if p.Tools.Run.KeepLastGood {
+		p.good = &good{}
+	}
+	// listening sockets passed to the processes
+	if len(p.Tools.Run.Sockets) > 0 {
+		p.sockets = &sockets{}
+	}
+	// memory limit of the whole project
+	if p.Limits.Memory > 0 {
+		if dir, err := cgroup(p.Name, p.Limits.Memory<<20); err == nil {
+			p.cgroup = dir
+		}
+	}
+	// reload in progress kept by the changes
+	var reloads chan bool
+	if p.Watcher.policy() != "restart" {
+		p.busy = &busy{done: make(chan bool, 1)}
+		reloads = p.busy.done
+	}
+	// hashes of the watched files
+	if p.Watcher.Semantic {
+		p.sources = &sources{files: make(map[string]source)}
+	}
 	// init a new watcher
 	p.watcher, err = EventWatcher()
 	if err != nil {
 		log.Fatal(err)
 	}
+	// test only changes channel
+	checks := make(chan bool)
+	// resource usage of the running executable
+	monitoring := make(chan bool)
+	if p.Tools.Run.Monitor.Status {
+		go p.monitor(monitoring)
+	}
 	defer func() {
 		close(p.stop)
+		close(checks)
+		close(monitoring)
+		p.retire()
+		p.unlisten()
+		p.unfuzz()
 		p.watcher.Close()
+		uncgroup(p.cgroup)
 	}()
 	// before start checks
 	p.Before()
 	// start watcher
-	go p.Reload("", p.stop)
+	p.start("")
 L:
 	for {
 		select {
@@ -294,6 +468,11 @@ L:
 			if p.parent.Settings.Recovery.Events {
 				log.Println("File:", event.Name, "LastFile:", p.last.file, "Time:", time.Now(), "LastTime:", p.last.time)
 			}
+			// files written by realize and its tools
+			if p.own(event.Name) {
+				p.index(event.Name)
+				continue
+			}
 			if time.Now().Truncate(time.Second).After(p.last.time) {
 				// switch event type
 				switch event.Op {
@@ -302,10 +481,8 @@ L:
 					p.watcher.Remove(event.Name)
 					if p.Validate(event.Name, false) && ext(event.Name) != "" {
 						// stop and restart
-						close(p.stop)
-						p.stop = make(chan bool)
 						p.Change(event)
-						go p.Reload("", p.stop)
+						p.reload("")
 					}
 				default:
 					if p.Validate(event.Name, true) {
@@ -315,18 +492,48 @@ L:
 						}
 						if fi.IsDir() {
 							filepath.Walk(event.Name, p.walk)
-						} else {
+							continue
+						}
+						kind := p.compare(event.Name)
+						if kind == unchanged {
+							continue
+						}
+						action, rule := p.action(event.Name, kind)
+						p.Change(event)
+						switch action {
+						case "restart":
+							if !p.hold(action, event.Name) {
+								p.rerun()
+							}
+						case "apply":
+							p.apply(rule, p.stop)
+						case "lint":
+							// the build is the same, only the source is checked
+							go p.lint(event.Name, fi, p.stop)
+							continue
+						case "retest":
+							if !p.hold(action, event.Name) {
+								checks = p.recheck(event.Name, checks)
+							}
+						default:
 							// stop and restart
-							close(p.stop)
-							p.stop = make(chan bool)
-							p.Change(event)
-							go p.Reload(event.Name, p.stop)
-							p.last.time = time.Now().Truncate(time.Second)
-							p.last.file = event.Name
+							p.reload(event.Name)
 						}
+						p.last.time = time.Now().Truncate(time.Second)
+						p.last.file = event.Name
 					}
 				}
 			}
+		case <-reloads:
+			// the queued change
+			switch action, path := p.reloaded(); action {
+			case "restart":
+				p.rerun()
+			case "retest":
+				checks = p.recheck(path, checks)
+			case "reload":
+				p.reload(path)
+			}
 		case err := <-p.watcher.Errors():
 			p.Err(err)
 		case <-p.exit:
@@ -337,6 +544,27 @@ L:
 	wg.Done()
 }
 
+// Action returns what the change of a file triggers: restart, apply a rule, lint, retest or reload
+func (p *Project) action(path string, kind int) (string, Rule) {
+	if rule, ok := p.rule(path); ok {
+		switch rule.kind() {
+		case "restart":
+			return "restart", rule
+		case "rebuild":
+			return "reload", rule
+		default:
+			return "apply", rule
+		}
+	}
+	switch {
+	case kind == cosmetic:
+		return "lint", Rule{}
+	case strings.HasSuffix(path, "_test.go"):
+		return "retest", Rule{}
+	}
+	return "reload", Rule{}
+}
+
 // Validate a file path
 func (p *Project) Validate(path string, fcheck bool) bool {
 	if len(path) == 0 {
@@ -346,8 +574,12 @@ func (p *Project) Validate(path string, fcheck bool) bool {
 	if p.Watcher.Hidden && isHidden(path) {
 		return false
 	}
+	// module files are always watched by the mod tool
+	mod := p.Tools.Mod.Status && module(path)
+	// files with a rule are watched whatever their extension
+	_, ruled := p.rule(path)
 	// check for a valid ext or path
-	if e := ext(path); e != "" {
+	if e := ext(path); e != "" && !mod && !ruled {
 		if len(p.Watcher.Exts) == 0 {
 			return false
 		}
@@ -403,21 +635,55 @@ func (p *Project) pname(name string, color int) string {
 	return name
 }
 
+// Lint runs fmt on a file and vet on its package
+func (p *Project) lint(path string, fi os.FileInfo, stop <-chan bool) {
+	p.tools(stop, path, fi, "Fmt")
+	p.pkg(path, stop, "Vet")
+}
+
+// Retest runs vet, the tests and the benchmarks of the package of a test file
+func (p *Project) retest(path string, stop <-chan bool) {
+	p.pkg(path, stop, "Vet", "Test", "Bench")
+	p.summary()
+}
+
+// Recheck stops the tests started by a previous change and runs the tests of a test file, it returns their stop
+func (p *Project) recheck(path string, checks chan bool) chan bool {
+	// tests aren't part of the build, the running process is kept
+	close(checks)
+	checks = make(chan bool)
+	// a newer build stops the tests too
+	go p.retest(path, either(checks, p.stop))
+	return checks
+}
+
+// Pkg runs some of the package tools on the directory of a file
+func (p *Project) pkg(path string, stop <-chan bool, only ...string) {
+	dir := filepath.Dir(path)
+	if di, err := os.Stat(dir); err == nil {
+		p.tools(stop, dir, di, only...)
+	}
+}
+
 //  Tool logs the result of a go command
-func (p *Project) tools(stop <-chan bool, path string, fi os.FileInfo) {
+func (p *Project) tools(stop <-chan bool, path string, fi os.FileInfo, only ...string) {
 	done := make(chan bool)
 	result := make(chan Response)
 	v := reflect.ValueOf(p.Tools)
 	go func() {
 		for i := 0; i < v.NumField()-1; i++ {
-			tool := v.Field(i).Interface().(Tool)
+			tool := p.Tools.tool(i)
 			tool.parent = p
+			if len(only) > 0 && !contains(only, tool.name) {
+				continue
+			}
 			if tool.Status && tool.isTool {
 				if fi.IsDir() {
 					if tool.dir {
 						result <- tool.Exec(path, stop)
 					}
-				} else if !tool.dir {
+				} else if !tool.dir || tool.generate {
+					// generate runs the directives fed by the file
 					result <- tool.Exec(path, stop)
 				}
 			}
@@ -455,7 +721,7 @@ func (p *Project) cmd(stop <-chan bool, flag string, global bool) {
 	go func() {
 		for _, cmd := range p.Watcher.Scripts {
 			if strings.ToLower(cmd.Type) == flag && cmd.Global == global {
-				result <- cmd.exec(p.Path, stop)
+				result <- cmd.exec(p, stop)
 			}
 		}
 		close(done)
@@ -491,7 +757,15 @@ func (p *Project) walk(path string, info os.FileInfo, err error) error {
 			if p.parent.Settings.Recovery.Index {
 				log.Println("Indexing", path)
 			}
-			p.tools(p.stop, path, info)
+			if !info.IsDir() {
+				p.index(path)
+			}
+			if info.IsDir() {
+				p.tools(p.stop, path, info)
+			} else if names := p.filetools(); len(names) > 0 {
+				// the directives of the file already ran with its directory
+				p.tools(p.stop, path, info, names...)
+			}
 			if info.IsDir() {
 				// tools dir
 				p.folders++
@@ -504,6 +778,18 @@ func (p *Project) walk(path string, info os.FileInfo, err error) error {
 	return nil
 }
 
+// Filetools returns the names of the tools run on single files, generate excluded
+func (p *Project) filetools() (names []string) {
+	v := reflect.ValueOf(p.Tools)
+	for i := 0; i < v.NumField()-1; i++ {
+		tool := p.Tools.tool(i)
+		if tool.Status && tool.isTool && !tool.dir {
+			names = append(names, tool.name)
+		}
+	}
+	return
+}
+
 func (p *Project) shouldIgnore(path string) bool {
 	separator := string(os.PathSeparator)
 	// supported paths
@@ -525,26 +811,17 @@ func (p *Project) stamp(t string, o BufferOut, msg string, stream string) {
 	case "out":
 		p.Buffer.StdOut = append(p.Buffer.StdOut, o)
 		if p.parent.Settings.Files.Outputs.Status {
-			f := p.parent.Settings.Create(p.Path, p.parent.Settings.Files.Outputs.Name)
-			if _, err := f.WriteString(strings.Join(content, " ")); err != nil {
-				p.parent.Settings.Fatal(err, "")
-			}
+			p.append(p.parent.Settings.Files.Outputs.Name, strings.Join(content, " "))
 		}
 	case "log":
 		p.Buffer.StdLog = append(p.Buffer.StdLog, o)
 		if p.parent.Settings.Files.Logs.Status {
-			f := p.parent.Settings.Create(p.Path, p.parent.Settings.Files.Logs.Name)
-			if _, err := f.WriteString(strings.Join(content, " ")); err != nil {
-				p.parent.Settings.Fatal(err, "")
-			}
+			p.append(p.parent.Settings.Files.Logs.Name, strings.Join(content, " "))
 		}
 	case "error":
 		p.Buffer.StdErr = append(p.Buffer.StdErr, o)
 		if p.parent.Settings.Files.Errors.Status {
-			f := p.parent.Settings.Create(p.Path, p.parent.Settings.Files.Errors.Name)
-			if _, err := f.WriteString(strings.Join(content, " ")); err != nil {
-				p.parent.Settings.Fatal(err, "")
-			}
+			p.append(p.parent.Settings.Files.Errors.Name, strings.Join(content, " "))
 		}
 	}
 	if msg != "" {
@@ -558,6 +835,19 @@ func (p *Project) stamp(t string, o BufferOut, msg string, stream string) {
 	}()
 }
 
+// Append a text to a log file of the project, the change event it emits is ignored
+func (p *Project) append(name string, text string) {
+	// the write is ignored by the watcher before it's recorded
+	done := p.writing(filepath.Join(p.Path, name))
+	defer done()
+	f := p.parent.Settings.Create(p.Path, name)
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		p.parent.Settings.Fatal(err, "")
+	}
+	p.appended(f.Name(), []byte(text))
+}
+
 func (p Project) buildEnvs() (envs []string) {
 	for k, v := range p.Env {
 		envs = append(envs, fmt.Sprintf("%s=%s", strings.Replace(k, "=", "", -1), v))
@@ -565,8 +855,8 @@ func (p Project) buildEnvs() (envs []string) {
 	return
 }
 
-// Run a project
-func (p *Project) run(path string, stream chan Response, stop <-chan bool) (err error) {
+// Run the project executable
+func (p *Project) run(path string, stream chan Response, stop <-chan bool, env ...string) (err error) {
 	var args []string
 	var build *exec.Cmd
 	var r Response
@@ -574,6 +864,23 @@ func (p *Project) run(path string, stream chan Response, stop <-chan bool) (err
 		// https://github.com/golang/go/issues/5615
 		// https://github.com/golang/go/issues/6720
 		if build != nil {
+			p.ended(build, stop)
+			if p.Tools.Run.Debug.Status {
+				// delve kills the debugged process it started when interrupted
+				interrupt(build)
+				done := make(chan bool)
+				go func() {
+					build.Process.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					kill(build)
+					<-done
+				}
+				return
+			}
 			build.Process.Signal(os.Interrupt)
 			build.Process.Wait()
 		}
@@ -598,37 +905,48 @@ func (p *Project) run(path string, stream chan Response, stop <-chan bool) (err
 		})
 		args = append(args, a...)
 	}
-	dirPath := os.Getenv("GOBIN")
-	if p.Tools.Run.Path != "" {
-		dirPath, _ = filepath.Abs(p.Tools.Run.Path)
-	}
-	name := filepath.Base(path)
-	if path == "." && p.Tools.Run.Path == "" {
-		name = filepath.Base(Wdir())
-	} else if p.Tools.Run.Path != "" {
-		name = filepath.Base(dirPath)
+	if path == "" {
+		return errors.New("project not found")
 	}
-	path = filepath.Join(dirPath, name)
-	if p.Tools.Run.Method != "" {
-		path = p.Tools.Run.Method
+	if _, err := os.Stat(path); err != nil {
+		return errors.New("project not found")
 	}
-	if _, err := os.Stat(path); err == nil {
-		build = exec.Command(path, args...)
-	} else if _, err := os.Stat(path + RExtWin); err == nil {
-		build = exec.Command(path+RExtWin, args...)
-	} else {
-		if _, err = os.Stat(path); err == nil {
-			build = exec.Command(path, args...)
-		} else if _, err = os.Stat(path + RExtWin); err == nil {
-			build = exec.Command(path+RExtWin, args...)
-		} else {
-			return errors.New("project not found")
+	if p.Tools.Run.Debug.Status {
+		if err := p.debuggable(); err != nil {
+			return err
 		}
+		cmd, err := p.Tools.Run.Debug.command(path, args)
+		if err != nil {
+			return err
+		}
+		build = cmd
+		group(build)
+	} else {
+		build = exec.Command(path, args...)
 	}
 	appendEnvs := p.buildEnvs()
 	if len(appendEnvs) > 0 {
 		build.Env = append(build.Env, appendEnvs...)
 	}
+	// variables of the blue/green slot
+	if len(env) > 0 {
+		if build.Env == nil {
+			build.Env = os.Environ()
+		}
+		build.Env = append(build.Env, env...)
+	}
+	// sockets owned by realize
+	if len(p.Tools.Run.Sockets) > 0 {
+		files, names, err := p.listen()
+		if err == nil {
+			err = handoff(build, files, names)
+		}
+		if err != nil {
+			build = nil
+			return err
+		}
+	}
+	p.confine(build, false)
 	// scan project stream
 	stdout, err := build.StdoutPipe()
 	stderr, err := build.StderrPipe()
@@ -641,6 +959,10 @@ func (p *Project) run(path string, stream chan Response, stop <-chan bool) (err
 	if err := build.Start(); err != nil {
 		return err
 	}
+	p.launched(build, stop)
+	if p.Tools.Run.Debug.Status {
+		p.debugging()
+	}
 	execOutput, execError := bufio.NewScanner(stdout), bufio.NewScanner(stderr)
 	stopOutput, stopError := make(chan bool, 1), make(chan bool, 1)
 	scanner := func(stop chan bool, output *bufio.Scanner, isError bool) {
@@ -686,7 +1008,8 @@ func (r *Response) print(start time.Time, p *Project) {
 }
 
 // Exec an additional command from a defined path if specified
-func (c *Command) exec(base string, stop <-chan bool) (response Response) {
+func (c *Command) exec(p *Project, stop <-chan bool) (response Response) {
+	base := p.Path
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	done := make(chan error)
@@ -703,6 +1026,7 @@ func (c *Command) exec(base string, stop <-chan bool) (response Response) {
 	}
 	ex.Stdout = &stdout
 	ex.Stderr = &stderr
+	p.confine(ex, false)
 	// Start command
 	ex.Start()
 	go func() { done <- ex.Wait() }()
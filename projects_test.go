@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"github.com/fsnotify/fsnotify"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -142,3 +145,71 @@ func TestProject_Watch(t *testing.T) {
 	r.Projects[0].Watch(&wg)
 	wg.Wait()
 }
+
+func TestProject_Retest(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":    "module example.com/a\n",
+		"a_test.go": "package a\nimport \"testing\"\nfunc TestFail(t *testing.T) { t.Fail() }\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test"}
+	p.Tools.Test = TestTool{Tool: Tool{Status: true}, Failfirst: true}
+	p.Tools.Build.Status = true
+	p.Tools.Setup()
+	p.retest(filepath.Join(dir, "a_test.go"), make(chan bool))
+	if p.outcomes == nil || !reflect.DeepEqual(p.outcomes.failing[dir], []string{"TestFail"}) {
+		t.Error("Expected the tests of the package run")
+	}
+}
+
+func TestProject_Action(t *testing.T) {
+	r := Realize{}
+	p := Project{parent: &r, Path: "/p"}
+	p.Watcher.Rules = []Rule{{Path: "web/**", Action: "restart"}, {Path: "conf/*", Signal: "HUP"}, {Path: "gen/*", Action: "rebuild"}}
+	cases := []struct {
+		path   string
+		kind   int
+		action string
+	}{
+		// the running process is kept for the tests
+		{"/p/a_test.go", modified, "retest"},
+		{"/p/a_test.go", cosmetic, "lint"},
+		{"/p/a.go", modified, "reload"},
+		{"/p/a.go", cosmetic, "lint"},
+		{"/p/web/a/index.html", modified, "restart"},
+		{"/p/conf/app.yaml", modified, "apply"},
+		// a rule is followed whatever the kind of change
+		{"/p/gen/a_test.go", modified, "reload"},
+		{"/p/gen/a.go", cosmetic, "reload"},
+	}
+	for _, c := range cases {
+		if action, _ := p.action(c.path, c.kind); action != c.action {
+			t.Error("Unexpected action", c.path, action, "expected", c.action)
+		}
+	}
+}
+
+func TestProject_ReloadTests(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":    "module example.com/a\n",
+		"a.go":      "package a\n\nfunc A() int { return 0 }\n",
+		"a_test.go": "package a\nimport \"testing\"\nfunc TestA(t *testing.T) { if A() != 1 { t.Fail() } }\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir}
+	p.Tools.Test = TestTool{Tool: Tool{Status: true}, Failfirst: true}
+	p.Tools.Setup()
+	file := filepath.Join(dir, "a.go")
+	p.Reload(file, make(chan bool))
+	if p.outcomes == nil || !reflect.DeepEqual(p.outcomes.failing[dir], []string{"TestA"}) {
+		t.Fatal("Expected the tests of the package run")
+	}
+	// fixing the code under test runs the failing tests again
+	ioutil.WriteFile(file, []byte("package a\n\nfunc A() int { return 1 }\n"), Permission)
+	p.Reload(file, make(chan bool))
+	if len(p.outcomes.failing[dir]) != 0 {
+		t.Error("Expected the tests fixed", p.outcomes.failing[dir])
+	}
+}
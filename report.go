@@ -0,0 +1,219 @@
+package realize
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report defines the summary of the test runs of a project
+type Report struct {
+	Status  bool   `yaml:"status,omitempty" json:"status,omitempty"`
+	Slowest int    `yaml:"slowest,omitempty" json:"slowest,omitempty"`
+	JUnit   string `yaml:"junit,omitempty" json:"junit,omitempty"`
+}
+
+// Suite aggregates the results of a package
+type suite struct {
+	pkg     string
+	action  string
+	elapsed float64
+	tests   []result
+	passed  int
+	failed  int
+	skipped int
+}
+
+// JUnit XML document
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// Merge the results of a run into the previous ones, tests run again are replaced
+func merge(old, new []result) []result {
+	replaced := make(map[string]bool)
+	for _, r := range new {
+		replaced[r.Package+" "+r.Test] = true
+	}
+	var merged []result
+	for _, r := range old {
+		if !replaced[r.Package+" "+r.Test] {
+			merged = append(merged, r)
+		}
+	}
+	return append(merged, new...)
+}
+
+// Suites groups results by package sorted by import path
+func suites(results []result) []*suite {
+	index := make(map[string]*suite)
+	var list []*suite
+	for _, r := range results {
+		s, ok := index[r.Package]
+		if !ok {
+			s = &suite{pkg: r.Package}
+			index[r.Package] = s
+			list = append(list, s)
+		}
+		if r.Test == "" {
+			s.action = r.Action
+			s.elapsed = r.Elapsed
+			continue
+		}
+		s.tests = append(s.tests, r)
+		switch r.Action {
+		case "pass":
+			s.passed++
+		case "fail":
+			s.failed++
+		case "skip":
+			s.skipped++
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].pkg < list[j].pkg })
+	return list
+}
+
+// Tree renders the summary of the suites
+func tree(name string, list []*suite, slowest int) (head string, body string) {
+	var passed, failed, skipped int
+	var elapsed float64
+	var lines []string
+	var all []result
+	for i, s := range list {
+		passed += s.passed
+		failed += s.failed
+		skipped += s.skipped
+		elapsed += s.elapsed
+		all = append(all, s.tests...)
+		branch, indent := "├─", "│ "
+		if i == len(list)-1 {
+			branch, indent = "└─", "  "
+		}
+		status := "ok"
+		if s.action == "fail" || s.failed > 0 {
+			status = "FAIL"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s %d passed %d failed %d skipped %.2fs", branch, status, s.pkg, s.passed, s.failed, s.skipped, s.elapsed))
+		for _, t := range s.tests {
+			if t.Action == "fail" {
+				lines = append(lines, fmt.Sprintf("%s   --- FAIL %s (%.2fs)", indent, t.Test, t.Elapsed))
+			}
+		}
+	}
+	head = fmt.Sprintf("%s %d packages %d passed %d failed %d skipped in %.2fs", name, len(list), passed, failed, skipped, elapsed)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Elapsed > all[j].Elapsed })
+	if len(all) > slowest {
+		all = all[:slowest]
+	}
+	if len(all) > 0 {
+		lines = append(lines, "slowest")
+	}
+	for _, t := range all {
+		lines = append(lines, fmt.Sprintf("  %.2fs %s %s", t.Elapsed, t.Package, t.Test))
+	}
+	return head, strings.Join(lines, "\n")
+}
+
+// Junit renders the suites as JUnit XML
+func junit(list []*suite) ([]byte, error) {
+	doc := junitSuites{}
+	for _, s := range list {
+		js := junitSuite{Name: s.pkg, Tests: len(s.tests), Failures: s.failed, Skipped: s.skipped, Time: fmt.Sprintf("%.3f", s.elapsed)}
+		for _, t := range s.tests {
+			c := junitCase{Name: t.Test, Classname: t.Package, Time: fmt.Sprintf("%.3f", t.Elapsed)}
+			switch t.Action {
+			case "fail":
+				c.Failure = &junitMessage{Message: "Failed", Body: t.Output}
+			case "skip":
+				c.Skipped = &junitMessage{Message: "Skipped", Body: t.Output}
+			}
+			js.Cases = append(js.Cases, c)
+		}
+		doc.Suites = append(doc.Suites, js)
+	}
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// Summary prints the test results of the project and writes the JUnit report, when tests ran since the last one
+func (p *Project) summary() {
+	t := p.Tools.Test
+	if !t.Status || !t.Report.Status || p.outcomes == nil {
+		return
+	}
+	o := p.outcomes
+	o.Lock()
+	if !o.fresh {
+		o.Unlock()
+		return
+	}
+	o.fresh = false
+	var results []result
+	dirs := make([]string, 0, len(o.results))
+	for dir := range o.results {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		results = append(results, o.results[dir]...)
+	}
+	o.Unlock()
+	if len(results) == 0 {
+		return
+	}
+	slowest := t.Report.Slowest
+	if slowest <= 0 {
+		slowest = 5
+	}
+	list := suites(results)
+	head, body := tree(p.Name, list, slowest)
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), Magenta.Regular(head))
+	out = BufferOut{Time: time.Now(), Text: head, Type: t.name, Stream: body}
+	p.stamp("log", out, msg, body)
+	if t.Report.JUnit == "" {
+		return
+	}
+	content, err := junit(list)
+	if err == nil {
+		file := t.Report.JUnit
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(p.Path, file)
+		}
+		p.wrote(file, content)
+		err = ioutil.WriteFile(file, content, Permission)
+	}
+	if err != nil {
+		p.Err(err)
+	}
+}
@@ -0,0 +1,72 @@
+package realize
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	old := []result{
+		{Package: "example.com/a", Test: "TestA", Action: "fail", Elapsed: 0.5},
+		{Package: "example.com/a", Test: "TestB", Action: "pass", Elapsed: 1},
+		{Package: "example.com/a", Action: "fail", Elapsed: 1.5},
+	}
+	results := merge(old, []result{
+		{Package: "example.com/a", Test: "TestA", Action: "pass", Elapsed: 0.1},
+		{Package: "example.com/a", Action: "pass", Elapsed: 0.2},
+		{Package: "example.com/a/b", Test: "TestC", Action: "fail", Elapsed: 2, Output: "boom & <fail>\n"},
+		{Package: "example.com/a/b", Test: "TestD", Action: "skip"},
+		{Package: "example.com/a/b", Action: "fail", Elapsed: 2.1},
+	})
+	if len(results) != 6 {
+		t.Fatal("Unexpected results", results)
+	}
+	list := suites(results)
+	if len(list) != 2 || list[0].passed != 2 || list[0].action != "pass" || list[1].failed != 1 || list[1].skipped != 1 {
+		t.Fatal("Unexpected suites", list[0], list[1])
+	}
+	head, body := tree("demo", list, 1)
+	if head != "demo 2 packages 2 passed 1 failed 1 skipped in 2.30s" {
+		t.Error("Unexpected head", head)
+	}
+	for _, v := range []string{"├─ ok example.com/a", "└─ FAIL example.com/a/b", "--- FAIL TestC (2.00s)", "2.00s example.com/a/b TestC"} {
+		if !strings.Contains(body, v) {
+			t.Error("Expected", v, "in", body)
+		}
+	}
+	if strings.Contains(body, "example.com/a TestB") {
+		t.Error("Unexpected slowest test in", body)
+	}
+	content, err := junit(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{`<testsuite name="example.com/a/b" tests="2" failures="1" skipped="1" time="2.100">`, `<failure message="Failed">boom &amp; &lt;fail&gt;`, `<skipped message="Skipped"></skipped>`} {
+		if !strings.Contains(string(content), v) {
+			t.Error("Expected", v, "in", string(content))
+		}
+	}
+}
+
+func TestProject_Summary(t *testing.T) {
+	dir := fixture(t, nil)
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir}
+	p.Tools.Test = TestTool{Tool: Tool{Status: true}, Report: Report{Status: true, JUnit: "junit.xml"}}
+	p.outcomes = &outcomes{results: make(map[string][]result)}
+	file := filepath.Join(dir, "junit.xml")
+	p.results(dir, []result{{Package: "example.com/a", Test: "TestA", Action: "pass"}})
+	p.summary()
+	if _, err := os.Stat(file); err != nil {
+		t.Fatal("Expected the report written", err)
+	}
+	// nothing new to report
+	os.Remove(file)
+	p.summary()
+	if _, err := os.Stat(file); err == nil {
+		t.Error("Unexpected report without new results")
+	}
+}
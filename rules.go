@@ -0,0 +1,198 @@
+package realize
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule defines the action run when a file matching a glob changes, ** matches any number of directories
+type Rule struct {
+	Path   string `yaml:"path" json:"path"`
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+	Signal string `yaml:"signal,omitempty" json:"signal,omitempty"`
+	Script string `yaml:"script,omitempty" json:"script,omitempty"`
+}
+
+// Process is the running project executable
+type process struct {
+	sync.Mutex
+	cmd *exec.Cmd
+}
+
+// Match reports whether a slash separated path matches a glob, ** matches zero or more elements
+func match(glob, path string) bool {
+	return matchParts(strings.Split(glob, "/"), strings.Split(path, "/"))
+}
+
+func matchParts(glob, path []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchParts(glob[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(glob[0], path[0]); !ok {
+			return false
+		}
+		glob, path = glob[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// Kind returns the action of the rule, a signal or a script alone define it
+func (r Rule) kind() string {
+	switch {
+	case r.Action != "":
+		return strings.ToLower(r.Action)
+	case r.Signal != "":
+		return "signal"
+	case r.Script != "":
+		return "script"
+	}
+	return "rebuild"
+}
+
+// Rule returns the first rule matching a file of the project
+func (p *Project) rule(path string) (Rule, bool) {
+	if len(p.Watcher.Rules) == 0 {
+		return Rule{}, false
+	}
+	base, _ := filepath.Abs(p.Path)
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return Rule{}, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, r := range p.Watcher.Rules {
+		if match(strings.TrimPrefix(r.Path, "./"), rel) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Started saves the running executable
+func (p *Project) started(cmd *exec.Cmd) {
+	if p.process == nil {
+		return
+	}
+	p.process.Lock()
+	p.process.cmd = cmd
+	p.process.Unlock()
+}
+
+// Exited forgets the executable if it's still the running one
+func (p *Project) exited(cmd *exec.Cmd) {
+	if p.process == nil {
+		return
+	}
+	p.process.Lock()
+	if p.process.cmd == cmd {
+		p.process.cmd = nil
+	}
+	p.process.Unlock()
+}
+
+// Signal sends a signal to the running executable
+func (p *Project) signal(name string) error {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := signals[name]
+	if !ok {
+		return errors.New("unsupported signal " + name)
+	}
+	if p.process == nil {
+		return errors.New("project not running")
+	}
+	p.process.Lock()
+	defer p.process.Unlock()
+	if p.process.cmd == nil || p.process.cmd.Process == nil {
+		return errors.New("project not running")
+	}
+	return p.process.cmd.Process.Signal(sig)
+}
+
+// Restart runs again the executable without building it
+func (p *Project) restart(stop <-chan bool) {
+	bin, err := p.binary()
+	if err != nil {
+		p.Err(err)
+		return
+	}
+	if p.deploy != nil {
+		// the serving artifact is copied to the other slot and started there next to the old process
+		p.deploy.Lock()
+		bin.slot = p.deploy.slot
+		p.deploy.Unlock()
+		serving := p.executable(bin)
+		bin.slot = p.next()
+		if target := p.executable(bin); target != serving {
+			if err := copyFile(serving, target); err != nil {
+				p.Err(err)
+				return
+			}
+		}
+		p.swap(bin, stop)
+		return
+	}
+	p.launch(bin, stop)
+}
+
+// Rerun stops the project and runs again the executable without building it
+func (p *Project) rerun() {
+	close(p.stop)
+	p.stop = make(chan bool)
+	go p.restart(p.stop)
+}
+
+// Script runs the script with the given name
+func (p *Project) script(name string, stop <-chan bool) {
+	for _, c := range p.Watcher.Scripts {
+		if c.Name != name {
+			continue
+		}
+		r := c.exec(p, stop)
+		msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold("Command"), Green.Bold("\"")+r.Name+Green.Bold("\""))
+		if r.Err != nil {
+			out = BufferOut{Time: time.Now(), Text: r.Err.Error(), Type: name}
+			p.stamp("error", out, msg, fmt.Sprint(Red.Regular(r.Err.Error())))
+		} else {
+			out = BufferOut{Time: time.Now(), Text: r.Out, Type: name}
+			p.stamp("log", out, msg, fmt.Sprint(r.Out))
+		}
+		return
+	}
+	p.Err(errors.New("script not found " + name))
+}
+
+// Apply runs the action of a rule that doesn't stop the project
+func (p *Project) apply(r Rule, stop <-chan bool) {
+	switch r.kind() {
+	case "none":
+	case "signal":
+		if err := p.signal(r.Signal); err != nil {
+			p.Err(err)
+			break
+		}
+		msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold("Signal"), Magenta.Bold(strings.ToUpper(r.Signal)), "sent")
+		out = BufferOut{Time: time.Now(), Text: "Signal " + strings.ToUpper(r.Signal) + " sent"}
+		p.stamp("log", out, msg, "")
+	case "script":
+		go p.script(r.Script, stop)
+	default:
+		p.Err(errors.New("unknown action " + r.Action))
+	}
+}
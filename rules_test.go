@@ -0,0 +1,59 @@
+package realize
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	data := []struct {
+		glob, path string
+		match      bool
+	}{
+		{"templates/**", "templates/a.html", true},
+		{"templates/**", "templates/a/b/c.html", true},
+		{"templates/**", "static/a.html", false},
+		{"config/*.yaml", "config/app.yaml", true},
+		{"config/*.yaml", "config/dev/app.yaml", false},
+		{"**/*.tmpl", "a.tmpl", true},
+		{"**/*.tmpl", "a/b/c.tmpl", true},
+		{"a/**/b/*.go", "a/b/c.go", true},
+		{"a/**/b/*.go", "a/x/y/b/c.go", true},
+		{"a/**/b/*.go", "a/x/y/c.go", false},
+	}
+	for _, d := range data {
+		if match(d.glob, d.path) != d.match {
+			t.Error("Unexpected match", d.glob, d.path)
+		}
+	}
+}
+
+func TestProject_Rule(t *testing.T) {
+	p := Project{Path: "/app", Watcher: Watch{Rules: []Rule{
+		{Path: "templates/**", Signal: "SIGHUP"},
+		{Path: "./config/*.yaml", Action: "restart"},
+		{Path: "scripts/*.sh", Script: "lint"},
+		{Path: "**/*.md", Action: "none"},
+	}}}
+	data := map[string]string{
+		"/app/templates/index.html": "signal",
+		"/app/config/app.yaml":      "restart",
+		"/app/scripts/a.sh":         "script",
+		"/app/docs/README.md":       "none",
+	}
+	for path, kind := range data {
+		r, ok := p.rule(filepath.FromSlash(path))
+		if !ok || r.kind() != kind {
+			t.Error("Unexpected rule", path, r)
+		}
+	}
+	if _, ok := p.rule("/app/main.go"); ok {
+		t.Error("Unexpected rule for main.go")
+	}
+	if _, ok := p.rule("/other/templates/a.html"); ok {
+		t.Error("Unexpected rule outside the project")
+	}
+	if p.signal("SIGNOPE") == nil || p.signal("HUP") == nil {
+		t.Error("Expected signal errors without a running project")
+	}
+}
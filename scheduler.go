@@ -0,0 +1,105 @@
+package realize
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler limits the tool processes running at the same time across the projects
+type scheduler struct {
+	sync.Mutex
+	max     int
+	running int
+	queue   []*job
+	changed map[*Project]time.Time
+}
+
+// Job is a tool process waiting for a slot
+type job struct {
+	project *Project
+	ready   chan bool
+}
+
+// Focus marks a project as the most recently changed
+func (s *scheduler) focus(p *Project) {
+	s.Lock()
+	s.changed[p] = time.Now()
+	s.Unlock()
+}
+
+// Acquire waits for a free slot, false if the job has been superseded by a newer change
+func (s *scheduler) acquire(p *Project, stop <-chan bool) bool {
+	s.Lock()
+	if s.running < s.max && len(s.queue) == 0 {
+		s.running++
+		s.Unlock()
+		return true
+	}
+	j := &job{project: p, ready: make(chan bool, 1)}
+	s.queue = append(s.queue, j)
+	s.Unlock()
+	select {
+	case <-j.ready:
+		return true
+	case <-stop:
+		s.Lock()
+		defer s.Unlock()
+		for i, q := range s.queue {
+			if q == j {
+				s.queue = append(s.queue[:i], s.queue[i+1:]...)
+				return false
+			}
+		}
+		// the slot has been granted meanwhile
+		s.running--
+		s.next()
+		return false
+	}
+}
+
+// Release frees a slot for the next job
+func (s *scheduler) release() {
+	s.Lock()
+	defer s.Unlock()
+	s.running--
+	s.next()
+}
+
+// Next grants the free slots to the jobs of the most recently changed projects first, the lock must be held
+func (s *scheduler) next() {
+	for s.running < s.max && len(s.queue) > 0 {
+		first := 0
+		for i, j := range s.queue {
+			if s.changed[j.project].After(s.changed[s.queue[first].project]) {
+				first = i
+			}
+		}
+		j := s.queue[first]
+		s.queue = append(s.queue[:first], s.queue[first+1:]...)
+		s.running++
+		j.ready <- true
+	}
+}
+
+// Focus gives priority to the jobs of the project
+func (p *Project) focus() {
+	if p.parent != nil && p.parent.scheduler != nil {
+		p.parent.scheduler.focus(p)
+	}
+}
+
+// Acquire waits for a slot of the global scheduler, false if the job has been stopped meanwhile
+func (p *Project) acquire(stop <-chan bool) bool {
+	if p == nil || p.parent == nil || p.parent.scheduler == nil {
+		return true
+	}
+	return p.parent.scheduler.acquire(p, stop)
+}
+
+// Release the slot of the global scheduler
+func (p *Project) release() {
+	if p == nil || p.parent == nil || p.parent.scheduler == nil {
+		return
+	}
+	p.parent.scheduler.release()
+}
@@ -0,0 +1,93 @@
+package realize
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	s := &scheduler{max: 1, changed: make(map[*Project]time.Time)}
+	a, b, c := &Project{Name: "a"}, &Project{Name: "b"}, &Project{Name: "c"}
+	if !s.acquire(a, nil) {
+		t.Fatal("Expected a free slot")
+	}
+	s.focus(b)
+	s.changed[c] = s.changed[b].Add(time.Second)
+	granted := make(chan string, 3)
+	stopB := make(chan bool)
+	wait := func(p *Project, stop chan bool) {
+		if s.acquire(p, stop) {
+			granted <- p.Name
+		} else {
+			granted <- "cancelled " + p.Name
+		}
+	}
+	go wait(b, stopB)
+	go wait(c, nil)
+	for {
+		s.Lock()
+		n := len(s.queue)
+		s.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// the most recently changed project goes first
+	s.release()
+	if name := <-granted; name != "c" {
+		t.Fatal("Expected the focused project first, got", name)
+	}
+	// a superseded job leaves the queue
+	close(stopB)
+	if name := <-granted; name != "cancelled b" {
+		t.Fatal("Expected the job to be cancelled, got", name)
+	}
+	s.release()
+	if s.running != 0 || len(s.queue) != 0 {
+		t.Error("Unexpected state", s.running, len(s.queue))
+	}
+}
+
+func TestProject_Acquire(t *testing.T) {
+	p := &Project{parent: &Realize{}}
+	// without a scheduler every job runs
+	if !p.acquire(nil) {
+		t.Error("Expected a slot without scheduler")
+	}
+	p.release()
+}
+
+func TestTool_CompileScheduled(t *testing.T) {
+	r := Realize{scheduler: &scheduler{max: 1, changed: make(map[*Project]time.Time)}}
+	p := &Project{parent: &r, Name: "test"}
+	r.scheduler.acquire(p, nil)
+	tool := Tool{parent: p, name: "Build", cmd: []string{"go", "version"}}
+	done := make(chan Response)
+	go func() { done <- tool.Compile(".", make(chan bool)) }()
+	select {
+	case <-done:
+		t.Fatal("Expected the build to wait for a slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+	r.scheduler.release()
+	if response := <-done; response.Err != nil {
+		t.Error("Unexpected error", response.Err)
+	}
+	// the mod commands wait for a slot too
+	r.scheduler.acquire(p, nil)
+	p.Tools.Mod.Status = true
+	p.Tools.Setup()
+	stop, result := make(chan bool), make(chan bool)
+	go func() { result <- p.modules(stop) }()
+	select {
+	case <-result:
+		t.Fatal("Expected the mod commands to wait for a slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(stop)
+	if <-result {
+		t.Error("Expected the mod commands stopped")
+	}
+	r.scheduler.release()
+}
@@ -0,0 +1,141 @@
+package realize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Kinds of change of a file
+const (
+	unchanged = iota
+	cosmetic
+	modified
+)
+
+// Sources keeps the hashes of the watched files
+type sources struct {
+	sync.Mutex
+	files map[string]source
+}
+
+// Source hashes, code is the hash of the go tokens without the comments
+type source struct {
+	content string
+	code    string
+}
+
+// Pragma reports whether a comment affects the build
+func pragma(comment string) bool {
+	for _, prefix := range []string{"//go:", "// +build", "//export ", "//line "} {
+		if strings.HasPrefix(comment, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Code returns the hash of the tokens of a go source, comments and spacing are ignored
+// except in the examples of the tests where they hold the expected output
+func code(content []byte, tests bool) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	// the cgo preamble is a comment
+	cgo := bytes.Contains(content, []byte(`"C"`))
+	var s scanner.Scanner
+	s.Init(file, content, nil, scanner.ScanComments)
+	h := sha256.New()
+	semicolon := false
+	// brace depth in the body of an example
+	prev, example, depth := token.ILLEGAL, false, 0
+	for {
+		_, tok, lit := s.Scan()
+		switch {
+		case !tests:
+		case prev == token.FUNC && tok == token.IDENT:
+			example = strings.HasPrefix(lit, "Example")
+		case example && tok == token.LBRACE:
+			depth++
+		case example && tok == token.RBRACE:
+			if depth--; depth == 0 {
+				example = false
+			}
+		}
+		if tok != token.COMMENT {
+			prev = tok
+		}
+		if tok == token.COMMENT && !cgo && !pragma(lit) && depth == 0 {
+			continue
+		}
+		if tok == token.SEMICOLON {
+			// semicolons are optional before a closing token
+			semicolon = true
+			continue
+		}
+		if semicolon && tok != token.RBRACE && tok != token.RPAREN && tok != token.EOF {
+			fmt.Fprintln(h, token.SEMICOLON)
+		}
+		semicolon = false
+		if tok == token.EOF {
+			break
+		}
+		fmt.Fprintln(h, tok, lit)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprints returns the hashes of a file
+func fingerprints(path string, content []byte) source {
+	s := source{content: digest(content)}
+	if filepath.Ext(path) == ".go" {
+		s.code = code(content, strings.HasSuffix(path, "_test.go"))
+	}
+	return s
+}
+
+// Index saves the hashes of a watched file
+func (p *Project) index(path string) {
+	if p.sources == nil {
+		return
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	s := fingerprints(path, content)
+	p.sources.Lock()
+	p.sources.files[path] = s
+	p.sources.Unlock()
+}
+
+// Compare returns the kind of change of a file since the last event and saves its hashes
+func (p *Project) compare(path string) int {
+	if p.sources == nil {
+		return modified
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return modified
+	}
+	cur := fingerprints(path, content)
+	p.sources.Lock()
+	prev, ok := p.sources.files[path]
+	p.sources.files[path] = cur
+	p.sources.Unlock()
+	switch {
+	case !ok:
+		return modified
+	case prev.content == cur.content:
+		return unchanged
+	case cur.code != "" && prev.code == cur.code:
+		return cosmetic
+	}
+	return modified
+}
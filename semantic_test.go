@@ -0,0 +1,75 @@
+package realize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	base := code([]byte("package a\n\nfunc A() int { return 1 }\n"), false)
+	same := []string{
+		"package a\n\n// A returns one\nfunc A() int {\n\treturn 1\n}\n",
+		"package a\n/* block */\nfunc  A() int { return 1 } // trailing\n",
+	}
+	for _, s := range same {
+		if code([]byte(s), false) != base {
+			t.Error("Unexpected code change", s)
+		}
+	}
+	different := []string{
+		"package a\n\nfunc A() int { return 2 }\n",
+		"package a\n\n//go:noinline\nfunc A() int { return 1 }\n",
+		"package a\n\nfunc A() int { return 1 }\n\nvar s = \"a  b\"\n",
+	}
+	for _, s := range different {
+		if code([]byte(s), false) == base {
+			t.Error("Expected code change", s)
+		}
+	}
+}
+
+func TestCode_Example(t *testing.T) {
+	example := "package a\n\n// ExampleA shows A\nfunc ExampleA() {\n\tfmt.Println(A())\n\t// Output: %d\n}\n\n// B %s\nfunc B() {}\n"
+	base := code([]byte(fmt.Sprintf(example, 1, "doc")), true)
+	// the expected output is part of the test
+	if code([]byte(fmt.Sprintf(example, 2, "doc")), true) == base {
+		t.Error("Expected the example output to be code")
+	}
+	// other comments aren't
+	if code([]byte(fmt.Sprintf(example, 1, "edited")), true) != base {
+		t.Error("Unexpected code change of a comment")
+	}
+	// outside the tests examples are plain functions
+	if code([]byte(fmt.Sprintf(example, 1, "doc")), false) != code([]byte(fmt.Sprintf(example, 2, "doc")), false) {
+		t.Error("Unexpected code change outside the tests")
+	}
+}
+
+func TestProject_Compare(t *testing.T) {
+	dir, err := ioutil.TempDir("", "semantic_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.go")
+	ioutil.WriteFile(file, []byte("package a\n"), Permission)
+	p := Project{sources: &sources{files: make(map[string]source)}}
+	p.index(file)
+	steps := []struct {
+		content string
+		kind    int
+	}{
+		{"package a\n", unchanged},
+		{"// Package a\npackage a\n", cosmetic},
+		{"// Package a\npackage a\n\nvar A int\n", modified},
+	}
+	for _, s := range steps {
+		ioutil.WriteFile(file, []byte(s.content), Permission)
+		if kind := p.compare(file); kind != s.kind {
+			t.Error("Unexpected change", s.content, kind)
+		}
+	}
+}
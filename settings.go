@@ -16,6 +16,7 @@ const (
 	FileOut    = ".r.outputs.log"
 	FileErr    = ".r.errors.log"
 	FileLog    = ".r.logs.log"
+	FileFlaky  = ".r.flaky.yaml"
 )
 
 // random string preference
@@ -30,6 +31,7 @@ const (
 type Settings struct {
 	Files     `yaml:"files,omitempty" json:"files,omitempty"`
 	FileLimit int32    `yaml:"flimit,omitempty" json:"flimit,omitempty"`
+	Jobs      int      `yaml:"jobs,omitempty" json:"jobs,omitempty"`
 	Recovery  Recovery `yaml:"recovery,omitempty" json:"recovery,omitempty"`
 }
 
@@ -0,0 +1,92 @@
+package realize
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Sockets are the listening sockets owned by realize and passed to every run process
+type sockets struct {
+	sync.Mutex
+	listeners []net.Listener
+	files     []*os.File
+	names     []string
+}
+
+// Address splits a socket address in network and address, tcp is the default network
+func address(s string) (network string, addr string) {
+	if i := strings.Index(s, "://"); i >= 0 {
+		return s[:i], s[i+3:]
+	}
+	return "tcp", s
+}
+
+// Listen opens the sockets of the project once, the same files are passed to every process
+func (p *Project) listen() ([]*os.File, []string, error) {
+	if p.sockets == nil {
+		return nil, nil, errors.New("sockets not available")
+	}
+	s := p.sockets
+	s.Lock()
+	defer s.Unlock()
+	if len(s.files) > 0 {
+		return s.files, s.names, nil
+	}
+	for _, socket := range p.Tools.Run.Sockets {
+		network, addr := address(socket)
+		if strings.HasPrefix(network, "unix") {
+			if !filepath.IsAbs(addr) {
+				addr = filepath.Join(p.Path, addr)
+			}
+			// stale socket of a previous session
+			os.Remove(addr)
+		}
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			s.close()
+			return nil, nil, err
+		}
+		s.listeners = append(s.listeners, l)
+		var f *os.File
+		switch l := l.(type) {
+		case *net.TCPListener:
+			f, err = l.File()
+		case *net.UnixListener:
+			f, err = l.File()
+		default:
+			err = errors.New("unsupported socket " + socket)
+		}
+		if err != nil {
+			s.close()
+			return nil, nil, err
+		}
+		s.files = append(s.files, f)
+		s.names = append(s.names, strings.NewReplacer(":", "_", "/", "_").Replace(socket))
+	}
+	return s.files, s.names, nil
+}
+
+// Close the sockets, the lock must be held
+func (s *sockets) close() {
+	for _, f := range s.files {
+		f.Close()
+	}
+	for _, l := range s.listeners {
+		l.Close()
+	}
+	s.files, s.listeners, s.names = nil, nil, nil
+}
+
+// Unlisten closes the sockets of the project
+func (p *Project) unlisten() {
+	if p.sockets == nil {
+		return
+	}
+	p.sockets.Lock()
+	p.sockets.close()
+	p.sockets.Unlock()
+}
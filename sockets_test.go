@@ -0,0 +1,39 @@
+package realize
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAddress(t *testing.T) {
+	data := map[string][2]string{
+		":8080":                {"tcp", ":8080"},
+		"tcp4://127.0.0.1:80":  {"tcp4", "127.0.0.1:80"},
+		"unix:///tmp/app.sock": {"unix", "/tmp/app.sock"},
+	}
+	for s, v := range data {
+		if network, addr := address(s); network != v[0] || addr != v[1] {
+			t.Error("Unexpected address", s, network, addr)
+		}
+	}
+}
+
+func TestProject_Listen(t *testing.T) {
+	p := Project{sockets: &sockets{}}
+	p.Tools.Run.Sockets = []string{"127.0.0.1:0"}
+	files, names, err := p.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.unlisten()
+	again, _, _ := p.listen()
+	if len(files) != 1 || len(names) != 1 || again[0] != files[0] {
+		t.Fatal("Unexpected sockets", files, names)
+	}
+	// connections are queued by the kernel until a process accepts them
+	conn, err := net.Dial("tcp", p.sockets.listeners[0].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
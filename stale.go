@@ -0,0 +1,45 @@
+package realize
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Good is the last successful build of the project
+type good struct {
+	sync.Mutex
+	bin  binary
+	time time.Time
+}
+
+// Succeeded records a successful build
+func (p *Project) succeeded(bin binary) {
+	if p.good == nil {
+		return
+	}
+	p.good.Lock()
+	p.good.bin, p.good.time = bin, time.Now()
+	p.good.Unlock()
+}
+
+// Stale keeps running the last successful build after a failure
+func (p *Project) stale(stop <-chan bool) {
+	if p.good == nil {
+		return
+	}
+	p.good.Lock()
+	bin, built := p.good.bin, p.good.time
+	p.good.Unlock()
+	if built.IsZero() {
+		return
+	}
+	text := "running stale build from " + built.Format("2006-01-02 15:04:05")
+	msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold("Run"), Red.Regular(text))
+	out = BufferOut{Time: time.Now(), Text: text, Type: "Go Run"}
+	p.stamp("error", out, msg, "")
+	// blue/green processes are still running
+	if p.deploy == nil {
+		p.launch(bin, stop)
+	}
+}
@@ -0,0 +1,20 @@
+package realize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProject_Stale(t *testing.T) {
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", good: &good{}, deploy: &deployment{}}
+	p.stale(nil)
+	if len(p.Buffer.StdErr) != 0 {
+		t.Error("Unexpected banner without a successful build")
+	}
+	p.succeeded(binary{pkg: "example.com/app"})
+	p.stale(nil)
+	if len(p.Buffer.StdErr) != 1 || !strings.HasPrefix(p.Buffer.StdErr[0].Text, "running stale build from ") {
+		t.Error("Expected stale build banner", p.Buffer.StdErr)
+	}
+}
@@ -0,0 +1,242 @@
+package realize
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event emitted by go test -json
+type event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// Result of a test or of a package when the test name is empty
+type result struct {
+	Package string
+	Test    string
+	Action  string
+	Elapsed float64
+	Output  string
+}
+
+// Outcomes keeps the test results of a session
+type outcomes struct {
+	sync.Mutex
+	failing map[string][]string
+	results map[string][]result
+	runs    map[string]run
+	pkgs    map[string]string
+	flaky   []quarantined
+	loaded  bool
+	invalid bool
+	fresh   bool
+}
+
+// ParseTests reads the results of go test -json, output is the plain text of the run
+func parseTests(r io.Reader) (results []result, output string) {
+	var text strings.Builder
+	outputs := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var e event
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &e) != nil {
+			text.Write(line)
+			text.WriteByte('\n')
+			continue
+		}
+		key := e.Package + " " + e.Test
+		switch e.Action {
+		case "output", "build-output":
+			text.WriteString(e.Output)
+			outputs[key] += e.Output
+		case "pass", "fail", "skip":
+			results = append(results, result{Package: e.Package, Test: e.Test, Action: e.Action, Elapsed: e.Elapsed, Output: outputs[key]})
+			delete(outputs, key)
+		}
+	}
+	return results, text.String()
+}
+
+// Failed returns the sorted top level tests that failed
+func failed(results []result) (tests []string) {
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if r.Action != "fail" || r.Test == "" {
+			continue
+		}
+		name := strings.SplitN(r.Test, "/", 2)[0]
+		if !seen[name] {
+			seen[name] = true
+			tests = append(tests, name)
+		}
+	}
+	sort.Strings(tests)
+	return
+}
+
+// Pattern returns the -run expression matching exactly the given tests
+func pattern(tests []string) string {
+	quoted := make([]string, len(tests))
+	for i, t := range tests {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// Difference returns the elements of a that aren't in b
+func difference(a, b []string) (diff []string) {
+	set := make(map[string]bool)
+	for _, v := range b {
+		set[v] = true
+	}
+	for _, v := range a {
+		if !set[v] {
+			diff = append(diff, v)
+		}
+	}
+	return
+}
+
+// Test runs go test -json, with failfirst the tests previously failing are run before the others
+func (p *Project) test(t *Tool, dir string, args []string, stop <-chan bool) (response Response, full bool) {
+	if p.outcomes == nil {
+		p.outcomes = &outcomes{
+			failing: make(map[string][]string),
+			results: make(map[string][]result),
+			runs:    make(map[string]run),
+			pkgs:    make(map[string]string),
+		}
+	}
+	o := p.outcomes
+	o.Lock()
+	prev, seen := o.failing[dir]
+	o.Unlock()
+	known := prev
+	response.Name = t.name
+	args = append(args, "-json")
+	// code version used to detect flaky tests
+	var hash string
+	if p.Tools.Test.Flaky.Status {
+		hash = fingerprint(dir)
+		if p.Tools.Test.Flaky.Mode == "skip" {
+			if skip := p.quarantined(dir); len(skip) > 0 {
+				p.report(t, dir, "skipping flaky", skip, false)
+				args = append(args, "-skip", pattern(skip))
+			}
+		}
+	}
+	if p.Tools.Test.Failfirst && len(prev) > 0 {
+		results, output, ok, err := t.tests(dir, append(args, "-run", pattern(prev)), stop)
+		if !ok {
+			return Response{}, false
+		}
+		p.results(dir, results)
+		if err != nil {
+			if still := failed(results); len(still) > 0 {
+				p.report(t, dir, "still failing", still, true)
+				p.report(t, dir, "fixed", difference(prev, still), false)
+				o.Lock()
+				o.failing[dir] = still
+				o.Unlock()
+			}
+			response.Err = errors.New(output + err.Error())
+			return response, false
+		}
+		p.report(t, dir, "fixed", prev, false)
+		prev = nil
+	}
+	results, output, ok, err := t.tests(dir, args, stop)
+	if !ok {
+		return Response{}, false
+	}
+	fails := failed(results)
+	if err != nil && len(fails) == 0 {
+		// build errors, previous failures are kept
+		response.Err = errors.New(output + err.Error())
+		return response, false
+	}
+	if p.Tools.Test.Flaky.Status {
+		if p.Tools.Test.Flaky.Mode == "retry" && len(fails) > 0 {
+			if results, ok = p.retry(t, dir, args, results, stop); !ok {
+				return Response{}, false
+			}
+			if fails = failed(results); len(fails) == 0 {
+				err = nil
+			}
+		} else if len(fails) > 0 {
+			var flaky []string
+			for _, test := range p.quarantined(dir) {
+				if contains(fails, test) {
+					flaky = append(flaky, test)
+				}
+			}
+			p.report(t, dir, "failing, quarantined as flaky", flaky, true)
+		}
+		p.detect(t, dir, hash, results)
+	}
+	p.results(dir, results)
+	if seen {
+		p.report(t, dir, "fixed", difference(prev, fails), false)
+		p.report(t, dir, "newly failing", difference(fails, known), true)
+	}
+	o.Lock()
+	o.failing[dir] = fails
+	o.Unlock()
+	if err != nil {
+		response.Err = errors.New(output + err.Error())
+	} else if t.Output {
+		response.Out = output
+	}
+	return response, true
+}
+
+// Results saves the latest results of the tests of a directory
+func (p *Project) results(dir string, results []result) {
+	o := p.outcomes
+	o.Lock()
+	o.results[dir] = merge(o.results[dir], results)
+	o.fresh = true
+	o.Unlock()
+}
+
+// Tests runs the tool and parses its results
+func (t *Tool) tests(dir string, args []string, stop <-chan bool) (results []result, output string, ok bool, err error) {
+	stdout, stderr, ok, err := t.command(dir, args, stop)
+	if !ok {
+		return
+	}
+	results, output = parseTests(strings.NewReader(stdout))
+	return results, stderr + output, true, err
+}
+
+// Report a list of tests
+func (p *Project) report(t *Tool, dir string, text string, tests []string, failure bool) {
+	if len(tests) == 0 {
+		return
+	}
+	list := strings.Join(tests, ", ")
+	if failure {
+		msg = fmt.Sprintln(p.pname(p.Name, 2), ":", Red.Bold(t.name), Red.Regular(text), ":", Magenta.Bold(dir))
+		out = BufferOut{Time: time.Now(), Text: text, Path: dir, Type: t.name, Stream: list}
+		p.stamp("error", out, msg, list)
+		return
+	}
+	msg = fmt.Sprintln(p.pname(p.Name, 5), ":", Green.Bold(t.name), Green.Regular(text), ":", Magenta.Bold(dir))
+	out = BufferOut{Time: time.Now(), Text: text, Path: dir, Type: t.name, Stream: list}
+	p.stamp("log", out, msg, list)
+}
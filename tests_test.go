@@ -0,0 +1,56 @@
+package realize
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTests(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/a","Test":"TestA"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestA/sub","Elapsed":0.1}
+{"Action":"fail","Package":"example.com/a","Test":"TestA","Elapsed":0.2}
+{"Action":"pass","Package":"example.com/a","Test":"TestB","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.3}
+`
+	results, text := parseTests(strings.NewReader(output))
+	if len(results) != 4 || text != "=== RUN   TestA\n" {
+		t.Fatal("Unexpected results", results, text)
+	}
+	if tests := failed(results); !reflect.DeepEqual(tests, []string{"TestA"}) {
+		t.Error("Unexpected failed tests", tests)
+	}
+	if p := pattern([]string{"TestA", "Test.B"}); p != `^(TestA|Test\.B)$` {
+		t.Error("Unexpected pattern", p)
+	}
+	if d := difference([]string{"a", "b", "c"}, []string{"b"}); !reflect.DeepEqual(d, []string{"a", "c"}) {
+		t.Error("Unexpected difference", d)
+	}
+}
+
+func TestProject_Test(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":    "module example.com/a\n",
+		"a_test.go": "package a\nimport \"testing\"\nfunc TestPass(t *testing.T) {}\nfunc TestFail(t *testing.T) { t.Fail() }\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test"}
+	p.Tools.Test = TestTool{Tool: Tool{Status: true}, Failfirst: true}
+	p.Tools.Setup()
+	tool := p.Tools.Test.Tool
+	response, full := p.test(&tool, dir, nil, nil)
+	if response.Err == nil || !full {
+		t.Fatal("Expected error", response)
+	}
+	if !reflect.DeepEqual(p.outcomes.failing[dir], []string{"TestFail"}) {
+		t.Error("Unexpected failing tests", p.outcomes.failing)
+	}
+	// the failing test is run first and stops the run
+	response, full = p.test(&tool, dir, nil, nil)
+	if response.Err == nil || full || strings.Contains(response.Err.Error(), "TestPass") {
+		t.Error("Unexpected response", response, full)
+	}
+}
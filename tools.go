@@ -5,43 +5,120 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
 // Tool info
 type Tool struct {
-	Args   []string `yaml:"args,omitempty" json:"args,omitempty"`
-	Method string   `yaml:"method,omitempty" json:"method,omitempty"`
-	Path   string   `yaml:"path,omitempty" json:"path,omitempty"`
-	Dir    string   `yaml:"dir,omitempty" json:"dir,omitempty"` //wdir of the command
-	Status bool     `yaml:"status,omitempty" json:"status,omitempty"`
-	Output bool     `yaml:"output,omitempty" json:"output,omitempty"`
-	dir    bool
-	isTool bool
-	method []string
-	cmd    []string
-	name   string
-	parent *Project
+	Args     []string `yaml:"args,omitempty" json:"args,omitempty"`
+	Method   string   `yaml:"method,omitempty" json:"method,omitempty"`
+	Path     string   `yaml:"path,omitempty" json:"path,omitempty"`
+	Dir      string   `yaml:"dir,omitempty" json:"dir,omitempty"` //wdir of the command
+	Status   bool     `yaml:"status,omitempty" json:"status,omitempty"`
+	Output   bool     `yaml:"output,omitempty" json:"output,omitempty"`
+	dir      bool
+	bench    bool
+	cover    bool
+	diff     bool
+	generate bool
+	json     bool
+	isTool   bool
+	method   []string
+	cmd      []string
+	env      []string
+	name     string
+	parent   *Project
 }
 
 // Tools go
 type Tools struct {
-	Clean    Tool `yaml:"clean,omitempty" json:"clean,omitempty"`
-	Vet      Tool `yaml:"vet,omitempty" json:"vet,omitempty"`
-	Fmt      Tool `yaml:"fmt,omitempty" json:"fmt,omitempty"`
-	Test     Tool `yaml:"test,omitempty" json:"test,omitempty"`
-	Generate Tool `yaml:"generate,omitempty" json:"generate,omitempty"`
-	Install  Tool `yaml:"install,omitempty" json:"install,omitempty"`
-	Build    Tool `yaml:"build,omitempty" json:"build,omitempty"`
-	Run      Tool `yaml:"run,omitempty" json:"run,omitempty"`
+	Clean    Tool      `yaml:"clean,omitempty" json:"clean,omitempty"`
+	Vet      Tool      `yaml:"vet,omitempty" json:"vet,omitempty"`
+	Fmt      FmtTool   `yaml:"fmt,omitempty" json:"fmt,omitempty"`
+	Test     TestTool  `yaml:"test,omitempty" json:"test,omitempty"`
+	Bench    BenchTool `yaml:"bench,omitempty" json:"bench,omitempty"`
+	Fuzz     FuzzTool  `yaml:"fuzz,omitempty" json:"fuzz,omitempty"`
+	Mod      ModTool   `yaml:"mod,omitempty" json:"mod,omitempty"`
+	Generate Tool      `yaml:"generate,omitempty" json:"generate,omitempty"`
+	Install  BuildTool `yaml:"install,omitempty" json:"install,omitempty"`
+	Build    BuildTool `yaml:"build,omitempty" json:"build,omitempty"`
+	Run      RunTool   `yaml:"run,omitempty" json:"run,omitempty"`
+}
+
+// FmtTool is the fmt tool and its formatting options
+type FmtTool struct {
+	Tool    `yaml:",inline"`
+	Mode    string  `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Imports Imports `yaml:"imports,omitempty" json:"imports,omitempty"`
+}
+
+// TestTool is the test tool and its options
+type TestTool struct {
+	Tool      `yaml:",inline"`
+	Cover     Cover  `yaml:"cover,omitempty" json:"cover,omitempty"`
+	Failfirst bool   `yaml:"failfirst,omitempty" json:"failfirst,omitempty"`
+	Flaky     Flaky  `yaml:"flaky,omitempty" json:"flaky,omitempty"`
+	Report    Report `yaml:"report,omitempty" json:"report,omitempty"`
+}
+
+// BenchTool is the bench tool and its options
+type BenchTool struct {
+	Tool       `yaml:",inline"`
+	Benchmarks Bench `yaml:"benchmarks,omitempty" json:"benchmarks,omitempty"`
+}
+
+// FuzzTool is the fuzz tool and its targets
+type FuzzTool struct {
+	Tool    `yaml:",inline"`
+	Fuzzing Fuzz `yaml:"fuzzing,omitempty" json:"fuzzing,omitempty"`
+}
+
+// ModTool is the mod tool and its commands
+type ModTool struct {
+	Tool    `yaml:",inline"`
+	Modules Mod `yaml:"modules,omitempty" json:"modules,omitempty"`
+}
+
+// BuildTool is the install or build tool and the options of its artifacts
+type BuildTool struct {
+	Tool   `yaml:",inline"`
+	Inject Inject   `yaml:"inject,omitempty" json:"inject,omitempty"`
+	Keep   int      `yaml:"keep,omitempty" json:"keep,omitempty"`
+	Matrix []Target `yaml:"matrix,omitempty" json:"matrix,omitempty"`
+}
+
+// RunTool is the run tool and the options of the running process
+type RunTool struct {
+	Tool         `yaml:",inline"`
+	Bluegreen    Bluegreen `yaml:"bluegreen,omitempty" json:"bluegreen,omitempty"`
+	Sockets      []string  `yaml:"sockets,omitempty" json:"sockets,omitempty"`
+	KeepLastGood bool      `yaml:"keep_last_good,omitempty" json:"keep_last_good,omitempty"`
+	Debug        Debug     `yaml:"debug,omitempty" json:"debug,omitempty"`
+	Monitor      Monitor   `yaml:"monitor,omitempty" json:"monitor,omitempty"`
+}
+
+// Tool returns a tool by index, the tools with options embed it
+func (t Tools) tool(i int) Tool {
+	v := reflect.ValueOf(t).Field(i)
+	if e := v.FieldByName("Tool"); e.IsValid() {
+		v = e
+	}
+	return v.Interface().(Tool)
 }
 
 // Setup go tools
 func (t *Tools) Setup() {
 	gocmd := "go"
 
+	// prevent errors using realize without config with only run flag
+	if t.Run.Status && !t.Install.Status && !t.Build.Status {
+		t.Build.Status = true
+	}
 	// go clean
 	if t.Clean.Status {
 		t.Clean.name = "Clean"
@@ -52,6 +129,7 @@ func (t *Tools) Setup() {
 	// go generate
 	if t.Generate.Status {
 		t.Generate.dir = true
+		t.Generate.generate = true
 		t.Generate.isTool = true
 		t.Generate.name = "Generate"
 		t.Generate.cmd = replace([]string{gocmd, "generate"}, t.Generate.Method)
@@ -59,12 +137,24 @@ func (t *Tools) Setup() {
 	}
 	// go fmt
 	if t.Fmt.Status {
+		formatter, args := "gofmt", []string{"-s", "-e"}
+		if t.Fmt.Imports.Status {
+			formatter, args = "goimports", []string{"-e"}
+			if t.Fmt.Imports.Local != "" {
+				args = append(args, "-local", t.Fmt.Imports.Local)
+			}
+		}
+		// check and fix modes never let the formatter write
+		t.Fmt.diff = t.Fmt.Mode == "check" || t.Fmt.Mode == "fix"
+		if !t.Fmt.diff {
+			args = append(args, "-w")
+		}
 		if len(t.Fmt.Args) == 0 {
-			t.Fmt.Args = []string{"-s", "-w", "-e"}
+			t.Fmt.Args = args
 		}
 		t.Fmt.name = "Fmt"
 		t.Fmt.isTool = true
-		t.Fmt.cmd = replace([]string{"gofmt"}, t.Fmt.Method)
+		t.Fmt.cmd = replace([]string{formatter}, t.Fmt.Method)
 		t.Fmt.Args = split([]string{}, t.Fmt.Args)
 	}
 	// go vet
@@ -82,6 +172,38 @@ func (t *Tools) Setup() {
 		t.Test.name = "Test"
 		t.Test.cmd = replace([]string{gocmd, "test"}, t.Test.Method)
 		t.Test.Args = split([]string{}, t.Test.Args)
+		t.Test.json = t.Test.Failfirst || t.Test.Flaky.Status || t.Test.Report.Status
+		t.Test.cover = t.Test.Cover.Status
+	}
+	// go test -bench
+	if t.Bench.Status {
+		regex := t.Bench.Benchmarks.Regex
+		if regex == "" {
+			regex = "."
+		}
+		// the comparison needs a few samples on each side to be significant
+		count := t.Bench.Benchmarks.Count
+		if count <= 0 {
+			count = 5
+		}
+		t.Bench.dir = true
+		t.Bench.bench = true
+		t.Bench.isTool = true
+		t.Bench.name = "Bench"
+		t.Bench.cmd = replace([]string{gocmd, "test", "-run", "^$", "-bench", regex, "-count", strconv.Itoa(count)}, t.Bench.Method)
+		t.Bench.Args = split([]string{}, t.Bench.Args)
+	}
+	// go test -fuzz
+	if t.Fuzz.Status {
+		t.Fuzz.name = "Fuzz"
+		t.Fuzz.cmd = replace([]string{gocmd, "test"}, t.Fuzz.Method)
+		t.Fuzz.Args = split([]string{}, t.Fuzz.Args)
+	}
+	// go mod
+	if t.Mod.Status {
+		t.Mod.name = "Mod"
+		t.Mod.cmd = replace([]string{gocmd, "mod"}, t.Mod.Method)
+		t.Mod.Args = split([]string{}, t.Mod.Args)
 	}
 	// go install
 	t.Install.name = "Install"
@@ -97,6 +219,7 @@ func (t *Tools) Setup() {
 
 // Exec a go tool
 func (t *Tool) Exec(path string, stop <-chan bool) (response Response) {
+	file := path
 	if t.dir {
 		if filepath.Ext(path) != "" {
 			path = filepath.Dir(path)
@@ -116,7 +239,7 @@ func (t *Tool) Exec(path string, stop <-chan bool) (response Response) {
 	} else if !strings.HasSuffix(path, ".go") {
 		return
 	}
-	args := t.Args
+	args := append([]string{}, t.Args...)
 	if strings.HasSuffix(path, ".go") {
 		args = append(args, path)
 		path = filepath.Dir(path)
@@ -125,64 +248,127 @@ func (t *Tool) Exec(path string, stop <-chan bool) (response Response) {
 		if t.parent.parent.Settings.Recovery.Tools {
 			log.Println("Tool:", t.name, path, args)
 		}
-		var out, stderr bytes.Buffer
-		done := make(chan error)
-		args = append(t.cmd, args...)
-		cmd := exec.Command(args[0], args[1:]...)
-		if t.Dir != "" {
-			cmd.Dir, _ = filepath.Abs(t.Dir)
-		} else {
-			cmd.Dir = path
+		// coverage profile
+		var profile string
+		if t.cover {
+			f, err := ioutil.TempFile("", "realize-cover")
+			if err != nil {
+				response.Name = t.name
+				response.Err = err
+				return
+			}
+			f.Close()
+			profile = f.Name()
+			defer os.Remove(profile)
+			args = append(args, "-coverprofile="+profile)
 		}
-		cmd.Stdout = &out
-		cmd.Stderr = &stderr
-		// Start command
-		err := cmd.Start()
-		if err != nil {
-			response.Name = t.name
-			response.Err = err
-			return
+		dir := path
+		if t.Dir != "" {
+			dir, _ = filepath.Abs(t.Dir)
 		}
-		go func() { done <- cmd.Wait() }()
-		// Wait a result
-		select {
-		case <-stop:
-			// Stop running command
-			cmd.Process.Kill()
-		case err := <-done:
-			// Command completed
-			response.Name = t.name
-			if err != nil {
-				response.Err = errors.New(stderr.String() + out.String() + err.Error())
-			} else {
-				if t.Output {
-					response.Out = out.String()
+		var ok bool
+		if t.json {
+			// tests reported from go test -json
+			response, ok = t.parent.test(t, dir, args, stop)
+		} else if t.diff {
+			// formatting reported as diff
+			response, ok = t.parent.format(t, dir, args, stop)
+		} else if t.generate {
+			// directives whose inputs changed
+			response, ok = t.parent.generate(t, path, file, args, stop)
+		} else {
+			var out, stderr string
+			var err error
+			done := func() {}
+			if !t.dir {
+				// the file may be rewritten, like by gofmt -w
+				done = t.parent.writing(file)
+			}
+			out, stderr, ok, err = t.command(dir, args, stop)
+			if ok {
+				response.Name = t.name
+				if err != nil {
+					response.Err = errors.New(stderr + out + err.Error())
+				} else if t.Output {
+					response.Out = out
+				}
+				if t.bench {
+					t.parent.benchmark(t, path, out)
+				}
+				if !t.dir {
+					t.parent.track(file)
 				}
 			}
+			done()
+		}
+		if ok && profile != "" {
+			t.parent.coverage(t, profile)
 		}
 	}
 	return
 }
 
-// Compile is used for build and install
-func (t *Tool) Compile(path string, stop <-chan bool) (response Response) {
+// Command runs the tool in a directory, ok is false if it has been stopped
+func (t *Tool) command(dir string, args []string, stop <-chan bool) (stdout, stderr string, ok bool, err error) {
+	var out, serr bytes.Buffer
+	done := make(chan error)
+	args = append(append([]string{}, t.cmd...), args...)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &serr
+	// background tools yield to the editor
+	t.parent.confine(cmd, true)
+	// wait for a slot of the scheduler
+	if !t.parent.acquire(stop) {
+		return "", "", false, nil
+	}
+	defer t.parent.release()
+	// Start command
+	if err = cmd.Start(); err != nil {
+		return "", "", true, err
+	}
+	go func() { done <- cmd.Wait() }()
+	// Wait a result
+	select {
+	case <-stop:
+		// Stop running command
+		cmd.Process.Kill()
+		<-done
+		return "", "", false, nil
+	case err = <-done:
+		// Command completed
+		return out.String(), serr.String(), true, err
+	}
+}
+
+// Compile is used for build and install, extra args are appended to the tool args
+func (t *Tool) Compile(path string, stop <-chan bool, extra ...string) (response Response) {
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	done := make(chan error)
-	args := append(t.cmd, t.Args...)
+	args := append(append(append([]string{}, t.cmd...), t.Args...), extra...)
 	cmd := exec.Command(args[0], args[1:]...)
 	if t.Dir != "" {
 		cmd.Dir, _ = filepath.Abs(t.Dir)
 	} else {
 		cmd.Dir = path
 	}
+	if len(t.env) > 0 {
+		cmd.Env = append(os.Environ(), t.env...)
+	}
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
+	t.parent.confine(cmd, false)
+	response.Name = t.name
+	if !t.parent.acquire(stop) {
+		return
+	}
+	defer t.parent.release()
 	// Start command
 	cmd.Start()
 	go func() { done <- cmd.Wait() }()
 	// Wait a result
-	response.Name = t.name
 	select {
 	case <-stop:
 		// Stop running command
@@ -1,6 +1,9 @@
 package realize
 
-import "testing"
+import (
+	"gopkg.in/yaml.v2"
+	"testing"
+)
 
 func TestTools_Setup(t *testing.T) {
 	tools := Tools{
@@ -23,3 +26,21 @@ func TestTools_Setup(t *testing.T) {
 		t.Error("Unexpected value")
 	}
 }
+
+func TestTools_Yaml(t *testing.T) {
+	var tools Tools
+	if err := yaml.Unmarshal([]byte("build:\n  status: true\n  keep: 3\n"), &tools); err != nil {
+		t.Fatal(err)
+	}
+	if !tools.Build.Status || tools.Build.Keep != 3 {
+		t.Fatal("Unexpected tools", tools)
+	}
+	// the options are written next to the tool fields
+	out, err := yaml.Marshal(Tools{Build: BuildTool{Tool: Tool{Status: true}, Keep: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "build:\n  status: true\n  keep: 3\n" {
+		t.Error("Unexpected config", string(out))
+	}
+}
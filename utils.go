@@ -1,6 +1,7 @@
 package realize
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -48,3 +49,34 @@ func Wdir() string {
 	}
 	return dir
 }
+
+// Either returns a channel closed when one of two channels is closed
+func either(a, b <-chan bool) <-chan bool {
+	c := make(chan bool)
+	go func() {
+		defer close(c)
+		select {
+		case <-a:
+		case <-b:
+		}
+	}()
+	return c
+}
+
+// Size formats a number of bytes in a human readable form
+func size(n int64) string {
+	const unit = 1024
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	if n < unit {
+		return fmt.Sprintf("%s%d B", sign, n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%s%.1f %cB", sign, float64(n)/float64(div), "KMGTPE"[exp])
+}
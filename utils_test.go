@@ -1,11 +1,29 @@
 package realize
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 )
 
+// Fixture writes the files of a test in a temporary directory
+func fixture(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "realize_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(file), Permission)
+		if err := ioutil.WriteFile(file, []byte(content), Permission); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
 func TestWdir(t *testing.T) {
 	expected, err := os.Getwd()
 	if err != nil {
@@ -34,3 +52,28 @@ func TestExt(t *testing.T) {
 	}
 
 }
+
+func TestSize(t *testing.T) {
+	sizes := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KB",
+		-2048:           "-2.0 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+	for i, v := range sizes {
+		if size(i) != v {
+			t.Error("Wrong size", size(i), v)
+		}
+	}
+}
+
+func TestEither(t *testing.T) {
+	a, b := make(chan bool), make(chan bool)
+	c := either(a, b)
+	close(b)
+	if _, ok := <-c; ok {
+		t.Error("Expected the channel closed")
+	}
+	close(a)
+}
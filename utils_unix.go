@@ -2,11 +2,31 @@
 
 package realize
 
-import "strings"
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Signals sent to the running executable by the rules
+var signals = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
 
 // isHidden check if a file or a path is hidden
 func isHidden(path string) bool {
-	arr := strings.Split(path[len(Wdir()):], "/")
+	// paths outside the working directory, like local modules, are checked entirely
+	if wd := Wdir(); path == wd || strings.HasPrefix(path, wd+"/") {
+		path = path[len(wd):]
+	}
+	arr := strings.Split(path, "/")
 	for _, elm := range arr {
 		if strings.HasPrefix(elm, ".") {
 			return true
@@ -14,3 +34,61 @@ func isHidden(path string) bool {
 	}
 	return false
 }
+
+// Group starts a command in its own process group
+func group(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// Interrupt the process group of a command started with group
+func interrupt(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// Kill the process group of a command started with group
+func kill(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// Handoff passes listening sockets to a command with the systemd socket activation protocol
+func handoff(cmd *exec.Cmd, files []*os.File, names []string) error {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return err
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, "LISTEN_FDS="+strconv.Itoa(len(files)), "LISTEN_FDNAMES="+strings.Join(names, ":"))
+	cmd.ExtraFiles = files
+	// the pid is known only by the process, exec keeps it
+	cmd.Args = append([]string{"sh", "-c", `LISTEN_PID=$$ exec "$0" "$@"`, cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sh
+	return nil
+}
+
+// Confine runs a command through a shell applying the limits, the prefix commands, like nice, wrap the process
+func confine(cmd *exec.Cmd, script string, prefix [][]string) error {
+	path, args := cmd.Path, cmd.Args[1:]
+	for i := len(prefix) - 1; i >= 0; i-- {
+		// prefix commands not installed are skipped
+		bin, err := exec.LookPath(prefix[i][0])
+		if err != nil {
+			continue
+		}
+		args = append(append(append([]string{}, prefix[i][1:]...), path), args...)
+		path = bin
+	}
+	cmd.Path, cmd.Args = path, append([]string{path}, args...)
+	if script == "" {
+		return nil
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return err
+	}
+	cmd.Args = append([]string{"sh", "-c", script}, cmd.Args...)
+	cmd.Path = sh
+	return nil
+}
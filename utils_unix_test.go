@@ -0,0 +1,45 @@
+// +build !windows
+
+package realize
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestHandoff(t *testing.T) {
+	p := Project{sockets: &sockets{}}
+	p.Tools.Run.Sockets = []string{"127.0.0.1:0"}
+	files, names, err := p.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.unlisten()
+	cmd := exec.Command("sh", "-c", `echo $LISTEN_PID $$ $LISTEN_FDS; test -e /dev/fd/3`)
+	if err := handoff(cmd, files, names); err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 || fields[0] != fields[1] || fields[2] != "1" {
+		t.Error("Unexpected environment", string(out))
+	}
+}
+
+func TestConfine(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "ulimit -n; echo $0", "args")
+	if err := confine(cmd, `ulimit -n 64; exec "$0" "$@"`, [][]string{{"nice", "-n", "5"}, {"missing-realize-tool"}}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := strings.Fields(string(out)); len(fields) != 2 || fields[0] != "64" || fields[1] != "args" {
+		t.Error("Unexpected output", string(out))
+	}
+}
@@ -2,7 +2,17 @@
 
 package realize
 
-import "syscall"
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Signals sent to the running executable by the rules, windows processes can only be killed
+var signals = map[string]os.Signal{
+	"SIGKILL": os.Kill,
+}
 
 // isHidden check if a file or a path is hidden
 func isHidden(path string) bool {
@@ -16,3 +26,26 @@ func isHidden(path string) bool {
 	}
 	return attrs&syscall.FILE_ATTRIBUTE_HIDDEN != 0
 }
+
+// Group starts a command in its own process group
+func group(cmd *exec.Cmd) {}
+
+// Interrupt a command, windows processes can't be interrupted so they are killed
+func interrupt(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// Kill a command
+func kill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// Handoff of listening sockets isn't supported on windows
+func handoff(cmd *exec.Cmd, files []*os.File, names []string) error {
+	return errors.New("sockets handoff not supported on windows")
+}
+
+// Confine isn't supported on windows
+func confine(cmd *exec.Cmd, script string, prefix [][]string) error {
+	return errors.New("process limits not supported on windows")
+}
@@ -0,0 +1,256 @@
+package realize
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Local is a module outside the project used through a replace directive or a workspace
+type local struct {
+	path string
+	dir  string
+}
+
+// Linked are the modules outside the project used by it and the workspace file listing them
+type linked struct {
+	sync.Mutex
+	work    string
+	modules []local
+}
+
+// Directives returns the arguments of the directives of a go.mod or go.work file, blocks included
+func directives(content []byte, verb string) (args [][]string) {
+	block := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case block && fields[0] == ")":
+			block = false
+		case block:
+			args = append(args, unquote(fields))
+		case fields[0] == verb && len(fields) == 2 && fields[1] == "(":
+			block = true
+		case fields[0] == verb:
+			args = append(args, unquote(fields[1:]))
+		}
+	}
+	return
+}
+
+// Unquote the fields of a directive
+func unquote(fields []string) []string {
+	for i, f := range fields {
+		if s, err := strconv.Unquote(f); err == nil {
+			fields[i] = s
+		}
+	}
+	return fields
+}
+
+// LocalPath reports whether a module path of a directive is a directory
+func localPath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || filepath.IsAbs(p)
+}
+
+// Modpath returns the module path declared in a go.mod file
+func modpath(content []byte) string {
+	for _, args := range directives(content, "module") {
+		if len(args) > 0 {
+			return args[0]
+		}
+	}
+	return ""
+}
+
+// Workfile returns the go.work file of a module, looked up in the parent directories unless set by GOWORK
+func workfile(base string) string {
+	switch env := os.Getenv("GOWORK"); {
+	case env == "off":
+		return ""
+	case env != "":
+		return env
+	}
+	base, _ = filepath.Abs(base)
+	for dir := base; ; dir = filepath.Dir(dir) {
+		file := filepath.Join(dir, "go.work")
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// Locals returns the modules outside the project replaced with a directory or used by the workspace
+func locals(base string) (result []local) {
+	base, _ = filepath.Abs(base)
+	var dirs []string
+	// relative paths are relative to the file declaring them
+	resolve := func(from, dir string) string {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(from, dir)
+		}
+		return filepath.Clean(dir)
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(base, "go.mod")); err == nil {
+		for _, args := range directives(content, "replace") {
+			// old [version] => new [version]
+			for i, arg := range args {
+				if arg == "=>" && i+1 < len(args) && localPath(args[i+1]) {
+					dirs = append(dirs, resolve(base, args[i+1]))
+				}
+			}
+		}
+	}
+	if work := workfile(base); work != "" {
+		if content, err := ioutil.ReadFile(work); err == nil {
+			for _, args := range directives(content, "use") {
+				if len(args) > 0 {
+					dirs = append(dirs, resolve(filepath.Dir(work), args[0]))
+				}
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		// modules inside the project are already watched
+		if seen[dir] || dir == base || strings.HasPrefix(dir, base+string(os.PathSeparator)) {
+			continue
+		}
+		seen[dir] = true
+		content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		result = append(result, local{path: modpath(content), dir: dir})
+	}
+	return
+}
+
+// Local returns the module outside the project containing a path
+func (p *Project) local(file string) (local, bool) {
+	if p.linked == nil {
+		return local{}, false
+	}
+	p.linked.Lock()
+	defer p.linked.Unlock()
+	for _, l := range p.linked.modules {
+		if file == l.dir || strings.HasPrefix(file, l.dir+string(os.PathSeparator)) {
+			return l, true
+		}
+	}
+	return local{}, false
+}
+
+// Dependents returns the directories of the project packages importing the package of a file of a local module
+func (p *Project) dependents(l local, file string) ([]string, error) {
+	dir := file
+	if fi, err := os.Stat(file); err != nil || !fi.IsDir() {
+		dir = filepath.Dir(file)
+	}
+	rel, err := filepath.Rel(l.dir, dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg := path.Join(l.path, filepath.ToSlash(rel))
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.Dir}}{{range .Deps}} {{.}}{{end}}", "./...")
+	cmd.Dir = p.Path
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(stderr.String() + err.Error())
+	}
+	var dirs []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, dep := range fields[1:] {
+			if dep == pkg {
+				dirs = append(dirs, fields[0])
+				break
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// Workfile reports whether a file is the workspace file of the project
+func (p *Project) workfile(file string) bool {
+	if p.linked == nil {
+		return false
+	}
+	p.linked.Lock()
+	defer p.linked.Unlock()
+	return p.linked.work != "" && p.linked.work == file
+}
+
+// Workspace watches the modules outside the project used by it, the ones not used anymore are unwatched
+func (p *Project) workspace() {
+	if p.linked == nil {
+		return
+	}
+	work, modules := workfile(p.Path), locals(p.Path)
+	p.linked.Lock()
+	prev, used := p.linked.work, p.linked.modules
+	p.linked.work, p.linked.modules = work, modules
+	p.linked.Unlock()
+	for _, l := range used {
+		kept := false
+		for _, m := range modules {
+			kept = kept || m.dir == l.dir
+		}
+		if !kept {
+			p.linkwalk(l, p.watcher.Remove)
+		}
+	}
+	if prev != work && prev != "" {
+		p.watcher.Remove(prev)
+	}
+	// a workspace in a parent directory isn't watched with the project
+	if base, _ := filepath.Abs(p.Path); work != "" && !strings.HasPrefix(work, base+string(os.PathSeparator)) {
+		p.watcher.Add(work)
+	}
+	for _, l := range modules {
+		p.linkwalk(l, func(path string) error {
+			p.watcher.Walk(path, p.init)
+			return nil
+		})
+	}
+}
+
+// Linkwalk calls a function on the watched paths of a local module
+func (p *Project) linkwalk(l local, fn func(string) error) {
+	filepath.Walk(l.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && path != l.dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if p.Validate(path, true) {
+			fn(path)
+		}
+		return nil
+	})
+}
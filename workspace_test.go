@@ -0,0 +1,116 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestDirectives(t *testing.T) {
+	content := []byte(`module example.com/app // app
+
+replace example.com/a => ../a
+
+replace (
+	example.com/b v1.0.0 => "../b" v1.0.0
+	example.com/c => example.com/d v1.2.0
+)
+`)
+	expected := [][]string{
+		{"example.com/a", "=>", "../a"},
+		{"example.com/b", "v1.0.0", "=>", "../b", "v1.0.0"},
+		{"example.com/c", "=>", "example.com/d", "v1.2.0"},
+	}
+	if args := directives(content, "replace"); !reflect.DeepEqual(args, expected) {
+		t.Error("Unexpected directives", args)
+	}
+	if modpath(content) != "example.com/app" {
+		t.Error("Unexpected module path", modpath(content))
+	}
+}
+
+func TestProject_Dependents(t *testing.T) {
+	root := fixture(t, map[string]string{
+		"app/go.mod":         "module example.com/app\n\ngo 1.13\n\nrequire example.com/shared v0.0.0\n\nreplace example.com/shared => ../shared\n",
+		"app/main.go":        "package main\n\nimport \"example.com/shared/greet\"\n\nfunc main() { greet.Hello() }\n",
+		"app/other/other.go": "package other\n",
+		"shared/go.mod":      "module example.com/shared\n\ngo 1.13\n",
+		"shared/greet/a.go":  "package greet\n\nfunc Hello() {}\n",
+		"work/go.work":       "go 1.18\n\nuse (\n\t.\n\t../shared\n)\n",
+	})
+	defer os.RemoveAll(root)
+	shared := local{path: "example.com/shared", dir: filepath.Join(root, "shared")}
+	if l := locals(filepath.Join(root, "app")); !reflect.DeepEqual(l, []local{shared}) {
+		t.Error("Unexpected replaced modules", l)
+	}
+	if l := locals(filepath.Join(root, "work")); !reflect.DeepEqual(l, []local{shared}) {
+		t.Error("Unexpected workspace modules", l)
+	}
+	p := Project{Path: filepath.Join(root, "app"), linked: &linked{modules: []local{shared}}}
+	file := filepath.Join(root, "shared", "greet", "a.go")
+	l, ok := p.local(file)
+	if !ok {
+		t.Fatal("Expected local module")
+	}
+	if _, ok := p.local(filepath.Join(root, "sharedx", "a.go")); ok {
+		t.Error("Unexpected local module")
+	}
+	dirs, err := p.dependents(l, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || filepath.Base(dirs[0]) != "app" {
+		t.Error("Unexpected dependents", dirs)
+	}
+}
+
+// Recorder is a watcher recording the watched paths
+type recorder struct {
+	paths map[string]bool
+}
+
+func (w *recorder) Close() error                    { return nil }
+func (w *recorder) Add(path string) error           { w.paths[path] = true; return nil }
+func (w *recorder) Walk(path string, _ bool) string { w.paths[path] = true; return path }
+func (w *recorder) Remove(path string) error        { delete(w.paths, path); return nil }
+func (w *recorder) Errors() <-chan error            { return nil }
+func (w *recorder) Events() <-chan fsnotify.Event   { return nil }
+
+func TestProject_Workspace(t *testing.T) {
+	os.Setenv("GOWORK", "")
+	root := fixture(t, map[string]string{
+		"go.work":       "go 1.18\n\nuse (\n\t./app\n\t./lib\n)\n",
+		"app/go.mod":    "module example.com/app\n\ngo 1.18\n",
+		"app/main.go":   "package main\n",
+		"lib/go.mod":    "module example.com/lib\n\ngo 1.18\n",
+		"lib/lib.go":    "package lib\n",
+		"other/go.mod":  "module example.com/other\n\ngo 1.18\n",
+		"other/main.go": "package other\n",
+	})
+	defer os.RemoveAll(root)
+	w := &recorder{paths: make(map[string]bool)}
+	r := Realize{}
+	p := Project{parent: &r, Path: filepath.Join(root, "app"), watcher: w, linked: &linked{}}
+	p.Watcher.Exts = []string{"go"}
+	p.workspace()
+	work, lib := filepath.Join(root, "go.work"), filepath.Join(root, "lib", "lib.go")
+	// the workspace of a parent directory is used and watched
+	if !w.paths[work] || !w.paths[lib] || !p.workfile(work) {
+		t.Fatal("Expected the workspace and its modules watched", w.paths)
+	}
+	// a module removed from the workspace is unwatched
+	ioutil.WriteFile(work, []byte("go 1.18\n\nuse (\n\t./app\n\t./other\n)\n"), 0644)
+	p.workspace()
+	if w.paths[lib] || !w.paths[filepath.Join(root, "other", "main.go")] {
+		t.Error("Unexpected watched paths", w.paths)
+	}
+	os.Setenv("GOWORK", "off")
+	defer os.Unsetenv("GOWORK")
+	if l := locals(p.Path); len(l) != 0 {
+		t.Error("Unexpected modules with the workspace disabled", l)
+	}
+}
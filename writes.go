@@ -0,0 +1,119 @@
+package realize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Writes keeps the files written by realize and its tools, pending are the files and directories being written
+type writes struct {
+	sync.Mutex
+	files   map[string]write
+	pending map[string]int
+}
+
+// Write is the hash of the last bytes written in a file of a given size
+type write struct {
+	size int64
+	tail int64
+	hash string
+}
+
+// Digest returns the hash of a content
+func digest(content []byte) string {
+	h := sha256.Sum256(content)
+	return hex.EncodeToString(h[:])
+}
+
+// Wrote records the content written by realize in a file, paths are absolute like the ones of the events
+func (p *Project) wrote(path string, content []byte) {
+	if p.written == nil {
+		return
+	}
+	path, _ = filepath.Abs(path)
+	p.written.Lock()
+	p.written.files[path] = write{size: int64(len(content)), tail: int64(len(content)), hash: digest(content)}
+	p.written.Unlock()
+}
+
+// Appended records the content appended by realize to a file, like the log files
+func (p *Project) appended(path string, content []byte) {
+	if p.written == nil {
+		return
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	path, _ = filepath.Abs(path)
+	p.written.Lock()
+	p.written.files[path] = write{size: fi.Size(), tail: int64(len(content)), hash: digest(content)}
+	p.written.Unlock()
+}
+
+// Writing marks a file or the files of a directory as being written until done is called,
+// their change events are ignored until the content is recorded
+func (p *Project) writing(path string) (done func()) {
+	if p.written == nil {
+		return func() {}
+	}
+	path, _ = filepath.Abs(path)
+	w := p.written
+	w.Lock()
+	if w.pending == nil {
+		w.pending = make(map[string]int)
+	}
+	w.pending[path]++
+	w.Unlock()
+	return func() {
+		w.Lock()
+		if w.pending[path]--; w.pending[path] <= 0 {
+			delete(w.pending, path)
+		}
+		w.Unlock()
+	}
+}
+
+// Track records the current content of a file a tool may have written
+func (p *Project) track(path string) {
+	if p.written == nil {
+		return
+	}
+	if content, err := ioutil.ReadFile(path); err == nil {
+		p.wrote(path, content)
+	}
+}
+
+// Own reports whether a file still has the content written by realize, the change events it emits are ignored
+func (p *Project) own(path string) bool {
+	if p.written == nil {
+		return false
+	}
+	w := p.written
+	w.Lock()
+	defer w.Unlock()
+	if w.pending[path] > 0 || w.pending[filepath.Dir(path)] > 0 {
+		return true
+	}
+	last, ok := w.files[path]
+	if !ok {
+		return false
+	}
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		fi, err := f.Stat()
+		if err == nil && fi.Size() == last.size {
+			tail := make([]byte, last.tail)
+			if _, err := f.ReadAt(tail, last.size-last.tail); err == nil && digest(tail) == last.hash {
+				return true
+			}
+		}
+	}
+	// changed by someone else
+	delete(w.files, path)
+	return false
+}
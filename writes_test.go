@@ -0,0 +1,78 @@
+package realize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProject_Own(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writes_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	r.Settings.Files.Logs = Resource{Status: true, Name: FileLog}
+	p := Project{parent: &r, Name: "test", Path: dir, written: &writes{files: make(map[string]write)}}
+	file := filepath.Join(dir, FileLog)
+	p.append(FileLog, "first\n")
+	p.append(FileLog, "second\n")
+	if !p.own(file) {
+		t.Error("Expected own log write")
+	}
+	f, _ := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, Permission)
+	f.WriteString("third\n")
+	f.Close()
+	if p.own(file) {
+		t.Error("Unexpected own write after an external append")
+	}
+	other := filepath.Join(dir, "a.go")
+	ioutil.WriteFile(other, []byte("package a\n"), Permission)
+	if p.own(other) {
+		t.Error("Unexpected own write of an untracked file")
+	}
+	p.track(other)
+	if !p.own(other) {
+		t.Error("Expected own write of a tracked file")
+	}
+}
+
+func TestProject_Writing(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod": "module example.com/a\n",
+		"a.go":   "package a\n\n//go:generate sh -c \"echo package a > gen.go; sleep 1\"\n",
+	})
+	defer os.RemoveAll(dir)
+	r := Realize{}
+	p := Project{parent: &r, Name: "test", Path: dir, written: &writes{files: make(map[string]write)}}
+	p.Tools.Generate = Tool{Status: true}
+	p.Tools.Setup()
+	tool := p.Tools.Generate
+	tool.parent = &p
+	done := make(chan Response)
+	go func() { done <- tool.Exec(dir, make(chan bool)) }()
+	gen := filepath.Join(dir, "gen.go")
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(gen); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the output of a generator still running isn't recorded yet
+	if !p.own(gen) {
+		t.Error("Expected own write of a running generator")
+	}
+	if r := <-done; r.Err != nil {
+		t.Fatal(r.Err)
+	}
+	if !p.own(gen) || len(p.written.pending) != 0 {
+		t.Error("Expected the output recorded once the generator ended")
+	}
+	ioutil.WriteFile(gen, []byte("package b\n"), Permission)
+	if p.own(gen) {
+		t.Error("Unexpected own write after the generator ended")
+	}
+}